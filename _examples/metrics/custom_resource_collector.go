@@ -26,15 +26,15 @@ var (
 	}
 
 	crCount = operatormetrics.NewGaugeVec(
-		operatormetrics.MetricOpts{
-			Name:        metricPrefix + "cr_count",
+		optsBuilder.Build(operatormetrics.MetricOpts{
+			Name:        "cr_count",
 			Help:        "Number of existing guestbook custom resources",
 			ConstLabels: map[string]string{"controller": "guestbook"},
 			ExtraFields: map[string]string{
 				"StabilityLevel":    "DEPRECATED",
 				"DeprecatedVersion": "1.14.0",
 			},
-		},
+		}),
 		[]string{"namespace"},
 	)
 )
@@ -4,9 +4,12 @@ import (
 	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
 )
 
-const metricPrefix = "guestbook_operator_"
+// NamePrefix is prepended to the names of all metrics
+const NamePrefix = "guestbook_operator_"
 
 var (
+	optsBuilder = operatormetrics.NewOptsBuilder(operatormetrics.WithNamePrefix(NamePrefix))
+
 	// Add your custom metrics here
 	metrics = [][]operatormetrics.Metric{
 		operatorMetrics,
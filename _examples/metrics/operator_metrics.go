@@ -9,8 +9,8 @@ var (
 	}
 
 	reconcileCount = operatormetrics.NewCounter(
-		operatormetrics.MetricOpts{
-			Name: metricPrefix + "reconcile_count",
+		optsBuilder.Build(operatormetrics.MetricOpts{
+			Name: "reconcile_count",
 			Help: "Number of times the operator has executed the reconcile loop",
 			ConstLabels: map[string]string{
 				"controller": "guestbook",
@@ -18,17 +18,21 @@ var (
 			ExtraFields: map[string]string{
 				"StabilityLevel": "STABLE",
 			},
-		},
+		}),
 	)
 
 	reconcileAction = operatormetrics.NewCounterVec(
-		operatormetrics.MetricOpts{
-			Name: metricPrefix + "reconcile_action_count",
+		optsBuilder.Build(operatormetrics.MetricOpts{
+			Name: "reconcile_action_count",
 			Help: "Number of times the operator has executed the reconcile loop with a given action",
 			ExtraFields: map[string]string{
 				"StabilityLevel": "ALPHA",
 			},
-		},
+			AllowedLabelValues: map[string][]string{
+				"action": {"create", "update", "delete", "sleep"},
+			},
+			LabelValuePolicy: operatormetrics.LabelValueCoerce,
+		}),
 		[]string{"action"},
 	)
 )
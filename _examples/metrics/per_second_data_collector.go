@@ -16,10 +16,10 @@ var (
 	}
 
 	perSecondData = operatormetrics.NewGaugeVec(
-		operatormetrics.MetricOpts{
-			Name: metricPrefix + "per_second_data",
+		optsBuilder.Build(operatormetrics.MetricOpts{
+			Name: "per_second_data",
 			Help: "Data per second",
-		},
+		}),
 		[]string{"source"},
 	)
 
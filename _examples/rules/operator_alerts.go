@@ -2,34 +2,27 @@ package rules
 
 import (
 	"fmt"
+	"time"
 
 	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/utils/ptr"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
 )
 
 var operatorAlerts = []promv1.Rule{
-	{
-		Alert: "GuestbookOperatorDown",
-		Expr:  intstr.FromString(fmt.Sprintf("%snumber_of_pods == 0", recordingRulesPrefix)),
-		Annotations: map[string]string{
-			"summary":     "Guestbook operator is down",
-			"description": "Guestbook operator is down for more than 5 minutes.",
-		},
-		Labels: map[string]string{
-			"severity": "critical",
-		},
-	},
-	{
-		Alert: "GuestbookOperatorNotReady",
-		Expr:  intstr.FromString(fmt.Sprintf("%snumber_of_ready_pods < %snumber_of_pods", recordingRulesPrefix, recordingRulesPrefix)),
-		For:   ptr.To(promv1.Duration("5m")),
-		Annotations: map[string]string{
-			"summary":     "Guestbook operator is not ready",
-			"description": "Guestbook operator is not ready for more than 5 minutes.",
-		},
-		Labels: map[string]string{
-			"severity": "critical",
-		},
-	},
+	operatorrules.Alert{
+		Name:        "GuestbookOperatorDown",
+		Expr:        fmt.Sprintf("%snumber_of_pods == 0", recordingRulesPrefix),
+		Severity:    operatorrules.SeverityCritical,
+		Summary:     "Guestbook operator is down",
+		Description: "Guestbook operator is down for more than 5 minutes.",
+	}.MustBuild(),
+	operatorrules.Alert{
+		Name:        "GuestbookOperatorNotReady",
+		Expr:        fmt.Sprintf("%snumber_of_ready_pods < %snumber_of_pods", recordingRulesPrefix, recordingRulesPrefix),
+		For:         5 * time.Minute,
+		Severity:    operatorrules.SeverityCritical,
+		Summary:     "Guestbook operator is not ready",
+		Description: "Guestbook operator is not ready for more than 5 minutes.",
+	}.MustBuild(),
 }
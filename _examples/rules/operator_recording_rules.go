@@ -12,7 +12,7 @@ import (
 var operatorRecordingRules = []operatorrules.RecordingRule{
 	{
 		MetricsOpts: operatormetrics.MetricOpts{
-			Name:        recordingRulesPrefix + "number_of_pods",
+			Name:        "number_of_pods",
 			Help:        "Number of guestbook operator pods in the cluster",
 			ConstLabels: map[string]string{"controller": "guestbook"},
 		},
@@ -21,7 +21,7 @@ var operatorRecordingRules = []operatorrules.RecordingRule{
 	},
 	{
 		MetricsOpts: operatormetrics.MetricOpts{
-			Name:        recordingRulesPrefix + "number_of_ready_pods",
+			Name:        "number_of_ready_pods",
 			Help:        "Number of ready guestbook operator pods in the cluster",
 			ExtraFields: map[string]string{"StabilityLevel": "ALPHA"},
 			ConstLabels: map[string]string{"controller": "guestbook"},
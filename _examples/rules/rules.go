@@ -2,6 +2,7 @@ package rules
 
 import (
 	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	promv1alpha1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1alpha1"
 
 	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
 )
@@ -12,7 +13,8 @@ const (
 )
 
 var (
-	operatorRegistry = operatorrules.NewRegistry()
+	// The prefix is prepended to the names of all recording rules
+	operatorRegistry = operatorrules.NewRegistry(operatorrules.WithNamePrefix(recordingRulesPrefix))
 
 	// Add your custom recording rules here
 	recordingRules = [][]operatorrules.RecordingRule{
@@ -50,6 +52,24 @@ func BuildPrometheusRule() (*promv1.PrometheusRule, error) {
 	return rules, nil
 }
 
+func BuildAlertmanagerConfig() (*promv1alpha1.AlertmanagerConfig, error) {
+	return operatorRegistry.BuildAlertmanagerConfig(
+		"guestbook-operator-alertmanager-config",
+		"default",
+		map[string]string{"app": "guestbook-operator"},
+		operatorrules.AlertmanagerOptions{
+			Receiver: "guestbook-operator",
+			SeverityReceivers: map[operatorrules.Severity]string{
+				operatorrules.SeverityCritical: "guestbook-operator-pager",
+			},
+		},
+	)
+}
+
+func Registry() *operatorrules.Registry {
+	return operatorRegistry
+}
+
 func ListRecordingRules() []operatorrules.RecordingRule {
 	return operatorRegistry.ListRecordingRules()
 }
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/machadovilaca/operator-observability/examples/metrics"
+	"github.com/machadovilaca/operator-observability/examples/rules"
+	"github.com/machadovilaca/operator-observability/pkg/dashboards"
+)
+
+func main() {
+	metrics.SetupMetrics()
+	rules.SetupRules()
+
+	dashboard, err := dashboards.Build(metrics.ListMetrics(), rules.ListRecordingRules(), dashboards.Options{
+		Title: "Guestbook Operator",
+		UID:   "guestbook-operator",
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	configMap, err := dashboards.BuildConfigMap("guestbook-operator-dashboard", "monitoring", nil, dashboard)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	out, err := yaml.Marshal(configMap)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	fmt.Print(string(out))
+}
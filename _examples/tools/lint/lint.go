@@ -4,14 +4,23 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/machadovilaca/operator-observability/examples/metrics"
 	"github.com/machadovilaca/operator-observability/examples/rules"
 	"github.com/machadovilaca/operator-observability/pkg/testutil"
 )
 
 func main() {
+	metrics.SetupMetrics()
 	rules.SetupRules()
-	alerts := rules.ListAlerts()
-	problems := testutil.New().LintAlerts(alerts)
+
+	linter := testutil.New()
+	linter.AddCustomMetricValidations(testutil.ValidateMetricNamePrefix(metrics.NamePrefix))
+	linter.AddCustomRecordRuleValidations(testutil.ValidateRecordingRuleNamePrefix(metrics.NamePrefix))
+	linter.AddCustomAlertValidations(testutil.ValidateAlertAnnotationTemplates, testutil.ValidateAlertAnnotationLabels)
+
+	problems := linter.LintMetrics(metrics.ListMetrics())
+	problems = append(problems, linter.LintRecordingRules(rules.ListRecordingRules())...)
+	problems = append(problems, linter.LintAlerts(rules.ListAlerts())...)
 
 	if len(problems) == 0 {
 		os.Exit(0)
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/machadovilaca/operator-observability/examples/rules"
+	"github.com/machadovilaca/operator-observability/pkg/manifests"
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+)
+
+func main() {
+	format := flag.String("format", "kustomize", "output format, kustomize or helm")
+	output := flag.String("output", "config/observability", "output directory")
+	flag.Parse()
+
+	rules.SetupRules()
+
+	opts := manifests.Options{
+		Name:      "guestbook-operator",
+		Namespace: "guestbook-operator",
+		Labels:    map[string]string{"app": "guestbook-operator"},
+		RBAC: operatorrules.RBACOptions{
+			PrometheusSAName:      "prometheus-k8s",
+			PrometheusSANamespace: "monitoring",
+		},
+		ServiceMonitor: operatorrules.MonitorOptions{
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "guestbook-operator"},
+			},
+		},
+	}
+
+	var err error
+	switch *format {
+	case "kustomize":
+		err = manifests.WriteKustomize(*output, rules.Registry(), opts)
+	case "helm":
+		err = manifests.WriteHelmChart(*output, rules.Registry(), opts)
+	default:
+		err = fmt.Errorf("unknown format %s", *format)
+	}
+
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/machadovilaca/operator-observability/examples/rules"
+	"github.com/machadovilaca/operator-observability/pkg/docs"
+)
+
+func main() {
+	output := flag.String("output", "docs/runbooks", "runbooks directory")
+	flag.Parse()
+
+	rules.SetupRules()
+
+	created, err := docs.GenerateRunbooks(rules.ListAlerts(), *output)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	for _, path := range created {
+		fmt.Printf("created %s\n", path)
+	}
+}
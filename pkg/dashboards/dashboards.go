@@ -0,0 +1,472 @@
+package dashboards
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+)
+
+const (
+	// SidecarLabel is the label the Grafana sidecar watches to provision the
+	// dashboards of ConfigMaps.
+	SidecarLabel      = "grafana_dashboard"
+	SidecarLabelValue = "1"
+
+	datasourceVariable = "datasource"
+	namespaceLabel     = "namespace"
+
+	panelWidth  = 12
+	panelHeight = 8
+	gridWidth   = 24
+)
+
+// DefaultQuantiles are the quantiles of the histogram quantile panels.
+var DefaultQuantiles = []float64{0.5, 0.9, 0.99}
+
+// Options configures the generated dashboard.
+type Options struct {
+	// Title of the dashboard, required.
+	Title string
+	// UID of the dashboard. When empty, Grafana generates one.
+	UID  string
+	Tags []string
+	// Quantiles of the histogram quantile panels. Defaults to
+	// DefaultQuantiles.
+	Quantiles []float64
+}
+
+// Dashboard is a Grafana dashboard JSON model.
+type Dashboard struct {
+	UID           string     `json:"uid,omitempty"`
+	Title         string     `json:"title"`
+	Tags          []string   `json:"tags,omitempty"`
+	Editable      bool       `json:"editable"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Time          TimeRange  `json:"time"`
+	Refresh       string     `json:"refresh"`
+	Templating    Templating `json:"templating"`
+	Panels        []Panel    `json:"panels"`
+}
+
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+// Variable is a dashboard template variable.
+type Variable struct {
+	Name       string         `json:"name"`
+	Label      string         `json:"label,omitempty"`
+	Type       string         `json:"type"`
+	Datasource *DatasourceRef `json:"datasource,omitempty"`
+	Query      string         `json:"query"`
+	Refresh    int            `json:"refresh,omitempty"`
+	IncludeAll bool           `json:"includeAll"`
+	Multi      bool           `json:"multi"`
+	AllValue   string         `json:"allValue,omitempty"`
+}
+
+type DatasourceRef struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+// Panel is a dashboard panel. Row panels group the panels below them.
+type Panel struct {
+	ID          int                    `json:"id"`
+	Type        string                 `json:"type"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description,omitempty"`
+	Datasource  *DatasourceRef         `json:"datasource,omitempty"`
+	GridPos     GridPos                `json:"gridPos"`
+	Targets     []Target               `json:"targets,omitempty"`
+	Options     map[string]interface{} `json:"options,omitempty"`
+}
+
+type GridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Target is a Prometheus query of a panel.
+type Target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+	Format       string `json:"format,omitempty"`
+}
+
+var prometheusDatasource = &DatasourceRef{Type: "prometheus", UID: "${" + datasourceVariable + "}"}
+
+// Build returns a Grafana dashboard for the given metrics and recording rules,
+// with a row of panels for each. Counters are shown as rate time series,
+// gauges as stat panels, or time series when they have labels, and histograms
+// as heatmap and quantile panels. The dashboard has template variables for the
+// Prometheus datasource, the namespace, and the labels of the Vec metrics.
+//
+// The namespace variable filters the metrics, which get the namespace label
+// of their scrape target, and the recording rules keeping it, as in a
+// namespace level or const label.
+func Build(metrics []operatormetrics.Metric, recordingRules []operatorrules.RecordingRule, opts Options) (*Dashboard, error) {
+	if opts.Title == "" {
+		return nil, errors.New("dashboard title is required")
+	}
+
+	if len(metrics) == 0 && len(recordingRules) == 0 {
+		return nil, errors.New("no metrics or recording rules to build the dashboard from")
+	}
+
+	quantiles := opts.Quantiles
+	if len(quantiles) == 0 {
+		quantiles = DefaultQuantiles
+	}
+
+	b := &builder{quantiles: quantiles, labelMetrics: map[string][]string{}}
+
+	var metricSeries []series
+	for _, metric := range metrics {
+		metricSeries = append(metricSeries, newSeries(metric.GetOpts(), metric.GetBaseType(), true))
+	}
+	sortSeries(metricSeries)
+	for _, s := range metricSeries {
+		b.collectLabels(s)
+	}
+
+	var ruleSeries []series
+	for _, rule := range recordingRules {
+		opts := rule.GetOpts()
+		ruleSeries = append(ruleSeries, newSeries(opts, baseType(rule.GetType()), keepsNamespace(opts)))
+	}
+	sortSeries(ruleSeries)
+
+	if len(metricSeries) > 0 {
+		b.addRow("Metrics")
+		for _, s := range metricSeries {
+			b.addMetricPanels(s)
+		}
+	}
+
+	if len(ruleSeries) > 0 {
+		b.addRow("Recording Rules")
+		for _, s := range ruleSeries {
+			b.addMetricPanels(s)
+		}
+	}
+
+	return &Dashboard{
+		UID:           opts.UID,
+		Title:         opts.Title,
+		Tags:          opts.Tags,
+		Editable:      true,
+		SchemaVersion: 38,
+		Time:          TimeRange{From: "now-6h", To: "now"},
+		Refresh:       "30s",
+		Templating:    Templating{List: b.variables(metricSeries, ruleSeries)},
+		Panels:        b.panels,
+	}, nil
+}
+
+// BuildJSON returns the Grafana dashboard of Build in JSON format.
+func BuildJSON(metrics []operatormetrics.Metric, recordingRules []operatorrules.RecordingRule, opts Options) ([]byte, error) {
+	dashboard, err := Build(metrics, recordingRules, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(dashboard, "", "  ")
+}
+
+// BuildConfigMap packages the dashboard as a ConfigMap with the SidecarLabel,
+// to be provisioned by the Grafana sidecar. The dashboard JSON is stored under
+// the name key with a .json extension.
+func BuildConfigMap(name, namespace string, labels map[string]string, dashboard *Dashboard) (*corev1.ConfigMap, error) {
+	data, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	configMapLabels := map[string]string{SidecarLabel: SidecarLabelValue}
+	for k, v := range labels {
+		configMapLabels[k] = v
+	}
+
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    configMapLabels,
+		},
+		Data: map[string]string{
+			name + ".json": string(data),
+		},
+	}, nil
+}
+
+// series is a metric or recording rule shown in the dashboard.
+type series struct {
+	opts       operatormetrics.MetricOpts
+	name       string
+	metricType operatormetrics.MetricType
+	// namespaced is whether the series have the namespace label.
+	namespaced bool
+}
+
+func newSeries(opts operatormetrics.MetricOpts, metricType operatormetrics.MetricType, namespaced bool) series {
+	return series{
+		opts:       opts,
+		name:       opts.Name,
+		metricType: metricType,
+		namespaced: namespaced || slices.Contains(opts.Labels(), namespaceLabel),
+	}
+}
+
+func sortSeries(list []series) {
+	slices.SortFunc(list, func(a, b series) int {
+		return strings.Compare(a.name, b.name)
+	})
+}
+
+// keepsNamespace reports whether a recording rule keeps the namespace label,
+// as in a namespace const label, or a level of a level:metric:operations name
+// aggregating by namespace.
+func keepsNamespace(opts operatormetrics.MetricOpts) bool {
+	if _, ok := opts.ConstLabels[namespaceLabel]; ok {
+		return true
+	}
+
+	level, _, found := strings.Cut(opts.Name, ":")
+	return found && slices.Contains(strings.Split(level, "_"), namespaceLabel)
+}
+
+type builder struct {
+	quantiles []float64
+	panels    []Panel
+	y         int
+	x         int
+
+	// labelMetrics holds the names of the metrics with each label.
+	labelMetrics map[string][]string
+	labelOrder   []string
+}
+
+func (b *builder) collectLabels(s series) {
+	for _, label := range s.opts.Labels() {
+		if label == namespaceLabel {
+			continue
+		}
+		if _, ok := b.labelMetrics[label]; !ok {
+			b.labelOrder = append(b.labelOrder, label)
+		}
+		b.labelMetrics[label] = append(b.labelMetrics[label], s.name)
+	}
+}
+
+func (b *builder) variables(metrics []series, recordingRules []series) []Variable {
+	var names []string
+	for _, s := range append(slices.Clone(metrics), recordingRules...) {
+		if s.namespaced {
+			names = append(names, seriesName(s.name, s.metricType))
+		}
+	}
+
+	variables := []Variable{
+		{
+			Name:  datasourceVariable,
+			Label: "Data source",
+			Type:  "datasource",
+			Query: "prometheus",
+		},
+	}
+	if len(names) > 0 {
+		variables = append(variables, queryVariable(namespaceLabel, names))
+	}
+
+	slices.Sort(b.labelOrder)
+	for _, label := range b.labelOrder {
+		var labelNames []string
+		for _, s := range metrics {
+			if slices.Contains(b.labelMetrics[label], s.name) {
+				labelNames = append(labelNames, seriesName(s.name, s.metricType))
+			}
+		}
+		variables = append(variables, queryVariable(label, labelNames))
+	}
+
+	return variables
+}
+
+func queryVariable(label string, metricNames []string) Variable {
+	return Variable{
+		Name:       label,
+		Label:      label,
+		Type:       "query",
+		Datasource: prometheusDatasource,
+		Query:      fmt.Sprintf(`label_values({__name__=~"%s"}, %s)`, strings.Join(metricNames, "|"), label),
+		Refresh:    2,
+		IncludeAll: true,
+		Multi:      true,
+		AllValue:   ".*",
+	}
+}
+
+func (b *builder) addRow(title string) {
+	if b.x != 0 {
+		b.x = 0
+		b.y += panelHeight
+	}
+
+	b.panels = append(b.panels, Panel{
+		ID:      len(b.panels) + 1,
+		Type:    "row",
+		Title:   title,
+		GridPos: GridPos{X: 0, Y: b.y, W: gridWidth, H: 1},
+	})
+	b.y++
+}
+
+func (b *builder) addPanel(panelType, title, description string, options map[string]interface{}, targets ...Target) {
+	b.panels = append(b.panels, Panel{
+		ID:          len(b.panels) + 1,
+		Type:        panelType,
+		Title:       title,
+		Description: description,
+		Datasource:  prometheusDatasource,
+		GridPos:     GridPos{X: b.x, Y: b.y, W: panelWidth, H: panelHeight},
+		Targets:     targets,
+		Options:     options,
+	})
+
+	b.x += panelWidth
+	if b.x >= gridWidth {
+		b.x = 0
+		b.y += panelHeight
+	}
+}
+
+func (b *builder) addMetricPanels(s series) {
+	name, opts := s.name, s.opts
+	labels := opts.Labels()
+	selector := labelSelector(labels, s.namespaced)
+	legend := legendFormat(labels)
+
+	switch s.metricType {
+	case operatormetrics.CounterType:
+		b.addPanel("timeseries", name, opts.Help, nil, Target{
+			RefID:        "A",
+			Expr:         aggregate(labels, fmt.Sprintf("rate(%s%s[$__rate_interval])", name, selector)),
+			LegendFormat: legend,
+		})
+	case operatormetrics.HistogramType:
+		b.addPanel("heatmap", name, opts.Help, map[string]interface{}{"calculate": false}, Target{
+			RefID:        "A",
+			Expr:         fmt.Sprintf("sum by (le) (rate(%s_bucket%s[$__rate_interval]))", name, selector),
+			LegendFormat: "{{le}}",
+			Format:       "heatmap",
+		})
+
+		var targets []Target
+		for i, q := range b.quantiles {
+			targets = append(targets, Target{
+				RefID: string(rune('A' + i)),
+				Expr: fmt.Sprintf("histogram_quantile(%g, sum by (%s) (rate(%s_bucket%s[$__rate_interval])))",
+					q, strings.Join(append(slices.Clone(labels), "le"), ", "), name, selector),
+				LegendFormat: strings.TrimSpace(fmt.Sprintf("p%.4g %s", q*100, legend)),
+			})
+		}
+		b.addPanel("timeseries", name+" quantiles", opts.Help, nil, targets...)
+	case operatormetrics.SummaryType:
+		b.addPanel("timeseries", name, opts.Help, nil, Target{
+			RefID:        "A",
+			Expr:         name + selector,
+			LegendFormat: strings.TrimSpace("{{quantile}} " + legend),
+		})
+	default:
+		if len(labels) == 0 {
+			b.addPanel("stat", name, opts.Help, nil, Target{
+				RefID: "A",
+				Expr:  fmt.Sprintf("sum(%s%s)", name, selector),
+			})
+			return
+		}
+		b.addPanel("timeseries", name, opts.Help, nil, Target{
+			RefID:        "A",
+			Expr:         aggregate(labels, name+selector),
+			LegendFormat: legend,
+		})
+	}
+}
+
+func labelSelector(labels []string, namespaced bool) string {
+	var matchers []string
+	if namespaced {
+		matchers = append(matchers, fmt.Sprintf(`%s=~"$%s"`, namespaceLabel, namespaceLabel))
+	}
+	for _, label := range labels {
+		if label != namespaceLabel {
+			matchers = append(matchers, fmt.Sprintf(`%s=~"$%s"`, label, label))
+		}
+	}
+	if len(matchers) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
+
+func aggregate(labels []string, expr string) string {
+	if len(labels) == 0 {
+		return fmt.Sprintf("sum(%s)", expr)
+	}
+	return fmt.Sprintf("sum by (%s) (%s)", strings.Join(labels, ", "), expr)
+}
+
+func legendFormat(labels []string) string {
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = "{{" + label + "}}"
+	}
+	return strings.Join(parts, " ")
+}
+
+// seriesName returns the name of a series of the metric, for histograms and
+// summaries, which have no series with the metric name alone.
+func seriesName(name string, metricType operatormetrics.MetricType) string {
+	switch metricType {
+	case operatormetrics.HistogramType, operatormetrics.SummaryType:
+		return name + "_count"
+	default:
+		return name
+	}
+}
+
+// baseType returns the base type of a metric type, with recording rules
+// without a type considered gauges.
+func baseType(metricType operatormetrics.MetricType) operatormetrics.MetricType {
+	switch metricType {
+	case operatormetrics.CounterType, operatormetrics.CounterVecType:
+		return operatormetrics.CounterType
+	case operatormetrics.HistogramType, operatormetrics.HistogramVecType:
+		return operatormetrics.HistogramType
+	case operatormetrics.SummaryType, operatormetrics.SummaryVecType:
+		return operatormetrics.SummaryType
+	default:
+		return operatormetrics.GaugeType
+	}
+}
@@ -0,0 +1,220 @@
+package dashboards_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/machadovilaca/operator-observability/pkg/dashboards"
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+)
+
+var metrics = []operatormetrics.Metric{
+	operatormetrics.NewCounterVec(
+		operatormetrics.MetricOpts{Name: "reconcile_total", Help: "Number of reconciles"},
+		[]string{"controller", "namespace"},
+	),
+	operatormetrics.NewGauge(
+		operatormetrics.MetricOpts{Name: "ready", Help: "Whether the operator is ready"},
+	),
+	operatormetrics.NewGaugeVec(
+		operatormetrics.MetricOpts{Name: "resources", Help: "Number of resources"},
+		[]string{"kind"},
+	),
+	operatormetrics.NewHistogram(
+		operatormetrics.MetricOpts{Name: "reconcile_duration_seconds", Help: "Reconcile duration"},
+		prometheus.HistogramOpts{},
+	),
+}
+
+var recordingRules = []operatorrules.RecordingRule{
+	{
+		MetricsOpts: operatormetrics.MetricOpts{Name: "number_of_pods", Help: "Number of pods"},
+		MetricType:  operatormetrics.GaugeType,
+		Expr:        intstr.FromString("count(up)"),
+	},
+}
+
+var _ = Describe("Dashboards", func() {
+	opts := dashboards.Options{Title: "Guestbook Operator", UID: "guestbook-operator"}
+
+	panelByTitle := func(dashboard *dashboards.Dashboard, title string) dashboards.Panel {
+		for _, panel := range dashboard.Panels {
+			if panel.Title == title {
+				return panel
+			}
+		}
+		Fail("panel " + title + " not found")
+		return dashboards.Panel{}
+	}
+
+	It("should build rows of panels sorted by name", func() {
+		dashboard, err := dashboards.Build(metrics, recordingRules, opts)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(dashboard.Title).To(Equal("Guestbook Operator"))
+		Expect(dashboard.UID).To(Equal("guestbook-operator"))
+
+		var titles []string
+		for _, panel := range dashboard.Panels {
+			titles = append(titles, panel.Type+" "+panel.Title)
+		}
+		Expect(titles).To(Equal([]string{
+			"row Metrics",
+			"stat ready",
+			"heatmap reconcile_duration_seconds",
+			"timeseries reconcile_duration_seconds quantiles",
+			"timeseries reconcile_total",
+			"timeseries resources",
+			"row Recording Rules",
+			"stat number_of_pods",
+		}))
+
+		for i, panel := range dashboard.Panels {
+			Expect(panel.ID).To(Equal(i + 1))
+		}
+		Expect(dashboard.Panels[1].GridPos).To(Equal(dashboards.GridPos{X: 0, Y: 1, W: 12, H: 8}))
+		Expect(dashboard.Panels[2].GridPos).To(Equal(dashboards.GridPos{X: 12, Y: 1, W: 12, H: 8}))
+		Expect(dashboard.Panels[6].GridPos).To(Equal(dashboards.GridPos{X: 0, Y: 25, W: 24, H: 1}))
+	})
+
+	It("should build rate panels for counters", func() {
+		dashboard, err := dashboards.Build(metrics, nil, opts)
+		Expect(err).ToNot(HaveOccurred())
+
+		panel := panelByTitle(dashboard, "reconcile_total")
+		Expect(panel.Description).To(Equal("Number of reconciles"))
+		Expect(panel.Targets).To(Equal([]dashboards.Target{{
+			RefID:        "A",
+			Expr:         `sum by (controller, namespace) (rate(reconcile_total{namespace=~"$namespace", controller=~"$controller"}[$__rate_interval]))`,
+			LegendFormat: "{{controller}} {{namespace}}",
+		}}))
+	})
+
+	It("should build stat and time series panels for gauges", func() {
+		dashboard, err := dashboards.Build(metrics, nil, opts)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(panelByTitle(dashboard, "ready").Targets[0].Expr).To(Equal(`sum(ready{namespace=~"$namespace"})`))
+		Expect(panelByTitle(dashboard, "resources").Targets[0].Expr).To(Equal(
+			`sum by (kind) (resources{namespace=~"$namespace", kind=~"$kind"})`))
+	})
+
+	It("should build heatmap and quantile panels for histograms", func() {
+		dashboard, err := dashboards.Build(metrics, nil, dashboards.Options{Title: "Guestbook Operator", Quantiles: []float64{0.5, 0.99}})
+		Expect(err).ToNot(HaveOccurred())
+
+		heatmap := panelByTitle(dashboard, "reconcile_duration_seconds")
+		Expect(heatmap.Type).To(Equal("heatmap"))
+		Expect(heatmap.Targets).To(Equal([]dashboards.Target{{
+			RefID:        "A",
+			Expr:         `sum by (le) (rate(reconcile_duration_seconds_bucket{namespace=~"$namespace"}[$__rate_interval]))`,
+			LegendFormat: "{{le}}",
+			Format:       "heatmap",
+		}}))
+
+		quantiles := panelByTitle(dashboard, "reconcile_duration_seconds quantiles")
+		Expect(quantiles.Targets).To(Equal([]dashboards.Target{
+			{
+				RefID:        "A",
+				Expr:         `histogram_quantile(0.5, sum by (le) (rate(reconcile_duration_seconds_bucket{namespace=~"$namespace"}[$__rate_interval])))`,
+				LegendFormat: "p50",
+			},
+			{
+				RefID:        "B",
+				Expr:         `histogram_quantile(0.99, sum by (le) (rate(reconcile_duration_seconds_bucket{namespace=~"$namespace"}[$__rate_interval])))`,
+				LegendFormat: "p99",
+			},
+		}))
+	})
+
+	It("should filter recording rules by namespace only when they keep it", func() {
+		registry := operatorrules.NewRegistry(operatorrules.WithNamePrefix("guestbook_operator_"))
+		Expect(registry.RegisterRecordingRules([]operatorrules.RecordingRule{
+			recordingRules[0],
+			{
+				MetricsOpts: operatormetrics.MetricOpts{Name: "namespace:ready_pods:sum"},
+				MetricType:  operatormetrics.GaugeType,
+				Expr:        intstr.FromString("sum by (namespace) (kube_pod_status_ready)"),
+			},
+		})).To(Succeed())
+
+		dashboard, err := dashboards.Build(nil, registry.ListRecordingRules(), opts)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(panelByTitle(dashboard, "guestbook_operator_number_of_pods").Targets[0].Expr).To(Equal(
+			"sum(guestbook_operator_number_of_pods)"))
+		Expect(panelByTitle(dashboard, "namespace:guestbook_operator_ready_pods:sum").Targets[0].Expr).To(Equal(
+			`sum(namespace:guestbook_operator_ready_pods:sum{namespace=~"$namespace"})`))
+
+		variables := dashboard.Templating.List
+		Expect(variables).To(HaveLen(2))
+		Expect(variables[1].Query).To(Equal(`label_values({__name__=~"namespace:guestbook_operator_ready_pods:sum"}, namespace)`))
+	})
+
+	It("should add template variables for the datasource, namespace and Vec labels", func() {
+		dashboard, err := dashboards.Build(metrics, recordingRules, opts)
+		Expect(err).ToNot(HaveOccurred())
+
+		variables := dashboard.Templating.List
+		Expect(variables).To(HaveLen(4))
+
+		Expect(variables[0].Name).To(Equal("datasource"))
+		Expect(variables[0].Type).To(Equal("datasource"))
+
+		Expect(variables[1].Name).To(Equal("namespace"))
+		Expect(variables[1].Query).To(Equal(
+			`label_values({__name__=~"ready|reconcile_duration_seconds_count|reconcile_total|resources"}, namespace)`))
+		Expect(variables[1].IncludeAll).To(BeTrue())
+		Expect(variables[1].AllValue).To(Equal(".*"))
+
+		Expect(variables[2].Name).To(Equal("controller"))
+		Expect(variables[2].Query).To(Equal(`label_values({__name__=~"reconcile_total"}, controller)`))
+		Expect(variables[3].Name).To(Equal("kind"))
+		Expect(variables[3].Query).To(Equal(`label_values({__name__=~"resources"}, kind)`))
+	})
+
+	It("should build the dashboard JSON", func() {
+		data, err := dashboards.BuildJSON(metrics, recordingRules, opts)
+		Expect(err).ToNot(HaveOccurred())
+
+		var dashboard map[string]interface{}
+		Expect(json.Unmarshal(data, &dashboard)).To(Succeed())
+		Expect(dashboard).To(HaveKeyWithValue("title", "Guestbook Operator"))
+		Expect(dashboard).To(HaveKey("panels"))
+		Expect(dashboard).To(HaveKey("templating"))
+	})
+
+	It("should package the dashboard in a ConfigMap with the sidecar label", func() {
+		dashboard, err := dashboards.Build(metrics, recordingRules, opts)
+		Expect(err).ToNot(HaveOccurred())
+
+		configMap, err := dashboards.BuildConfigMap("guestbook-operator-dashboard", "monitoring", map[string]string{"app": "guestbook"}, dashboard)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(configMap.Kind).To(Equal("ConfigMap"))
+		Expect(configMap.Name).To(Equal("guestbook-operator-dashboard"))
+		Expect(configMap.Namespace).To(Equal("monitoring"))
+		Expect(configMap.Labels).To(Equal(map[string]string{"app": "guestbook", "grafana_dashboard": "1"}))
+		Expect(configMap.Data).To(HaveKey("guestbook-operator-dashboard.json"))
+
+		var decoded dashboards.Dashboard
+		Expect(json.Unmarshal([]byte(configMap.Data["guestbook-operator-dashboard.json"]), &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(*dashboard))
+	})
+
+	It("should return an error without a title", func() {
+		_, err := dashboards.Build(metrics, nil, dashboards.Options{})
+		Expect(err).To(MatchError("dashboard title is required"))
+	})
+
+	It("should return an error without metrics and recording rules", func() {
+		_, err := dashboards.Build(nil, nil, opts)
+		Expect(err).To(HaveOccurred())
+	})
+})
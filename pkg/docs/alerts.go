@@ -79,10 +79,12 @@ func buildAlertsDocs(alerts []promv1.Rule) []alertDocs {
 		alertsDocs[i] = alertDocs{
 			Name:        alert.Alert,
 			Expr:        alert.Expr.String(),
-			For:         string(*alert.For),
 			Annotations: alert.Annotations,
 			Labels:      alert.Labels,
 		}
+		if alert.For != nil {
+			alertsDocs[i].For = string(*alert.For)
+		}
 	}
 	sortAlertsDocs(alertsDocs)
 
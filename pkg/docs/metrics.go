@@ -19,6 +19,10 @@ const defaultMetricsTemplate = `# Operator Metrics
 {{.Help}}.
 
 Type: {{.Type}}.
+{{- range $label, $values := .AllowedLabelValues }}
+
+Allowed values for label ` + "`{{ $label }}`" + `: {{ range $i, $value := $values }}{{ if $i }}, {{ end }}` + "`{{ $value }}`" + `{{ end }}.
+{{- end }}
 {{- end }}
 
 ## Developing new metrics
@@ -29,10 +33,11 @@ this document.
 `
 
 type metricDocs struct {
-	Name        string
-	Help        string
-	Type        string
-	ExtraFields map[string]string
+	Name               string
+	Help               string
+	Type               string
+	ExtraFields        map[string]string
+	AllowedLabelValues map[string][]string
 }
 
 type docOptions interface {
@@ -89,10 +94,11 @@ func buildMetricsDocs[T docOptions](items []T) []metricDocs {
 		if _, exists := uniqueNames[metricOpts.Name]; !exists {
 			uniqueNames[metricOpts.Name] = struct{}{}
 			metricsDocs = append(metricsDocs, metricDocs{
-				Name:        metricOpts.Name,
-				Help:        metricOpts.Help,
-				Type:        getAndConvertMetricType(metric.GetType()),
-				ExtraFields: metricOpts.ExtraFields,
+				Name:               metricOpts.Name,
+				Help:               metricOpts.Help,
+				Type:               getAndConvertMetricType(metric.GetType()),
+				ExtraFields:        metricOpts.ExtraFields,
+				AllowedLabelValues: metricOpts.AllowedLabelValues,
 			})
 		}
 	}
@@ -102,5 +102,26 @@ var _ = Describe("Metrics Documentation", func() {
 			Expect(templateDocMetrics).To(ContainSubstring("BExampleGauge\ntest doc gauge. Type: Gauge."))
 			Expect(templateDocMetrics).To(ContainSubstring("[ALPHA in 1.4.0] test doc counterVec. Type: Counter."))
 		})
+
+		It("Checks that the allowed label values are documented", func() {
+			docMetrics := docs.BuildMetricsDocs([]operatormetrics.Metric{
+				operatormetrics.NewCounterVec(
+					operatormetrics.MetricOpts{
+						Name: "EExampleCounterVec",
+						Help: "test doc counterVec with allowed values",
+						AllowedLabelValues: map[string][]string{
+							"action": {"create", "delete"},
+							"phase":  {"Running"},
+						},
+					},
+					[]string{"action", "phase"},
+				),
+			}, nil)
+			Expect(docMetrics).To(ContainSubstring("### EExampleCounterVec\n" +
+				"test doc counterVec with allowed values.\n\n" +
+				"Type: Counter.\n\n" +
+				"Allowed values for label `action`: `create`, `delete`.\n\n" +
+				"Allowed values for label `phase`: `Running`.\n"))
+		})
 	})
 })
@@ -0,0 +1,88 @@
+package docs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+)
+
+const defaultRunbookTemplate = `# {{.Name}}
+
+## Meaning
+
+{{ with index .Annotations "description" }}{{ . }}{{ else }}{{ index .Annotations "summary" }}{{ end }}
+
+## Impact
+
+<!-- Describe the impact of this alert on the operator and its workloads. -->
+
+## Diagnosis
+
+<!-- Describe the steps to find the cause of this alert. -->
+
+## Mitigation
+
+<!-- Describe the steps to resolve the issue that triggered this alert. -->
+`
+
+// RunbookFileName returns the name of the runbook file for the given alert.
+func RunbookFileName(alertName string) string {
+	return alertName + ".md"
+}
+
+// GenerateRunbooksWithCustomTemplate creates a runbook skeleton in dir, using
+// the given template, for each alert that does not already have one. Existing
+// runbooks are never overwritten. It returns the paths of the created files.
+func GenerateRunbooksWithCustomTemplate(
+	alerts []promv1.Rule,
+	dir string,
+	tplString string,
+) ([]string, error) {
+
+	tpl, err := template.New("runbook").Parse(tplString)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var created []string
+	for _, doc := range buildAlertsDocs(alerts) {
+		path := filepath.Join(dir, RunbookFileName(doc.Name))
+
+		_, err := os.Stat(path)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return created, err
+		}
+
+		buf := bytes.NewBufferString("")
+		if err := tpl.Execute(buf, doc); err != nil {
+			return created, fmt.Errorf("failed to render runbook for %s: %w", doc.Name, err)
+		}
+
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			return created, err
+		}
+		created = append(created, path)
+	}
+
+	return created, nil
+}
+
+// GenerateRunbooks creates a runbook skeleton in dir, with meaning, impact,
+// diagnosis and mitigation sections, for each alert that does not already have
+// one. It returns the paths of the created files.
+func GenerateRunbooks(alerts []promv1.Rule, dir string) ([]string, error) {
+	return GenerateRunbooksWithCustomTemplate(alerts, dir, defaultRunbookTemplate)
+}
@@ -0,0 +1,95 @@
+package docs_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/machadovilaca/operator-observability/pkg/docs"
+)
+
+var alerts = []promv1.Rule{
+	{
+		Alert: "ExampleAlert",
+		Expr:  intstr.FromString("sum(rate(http_requests_total[5m])) > 10"),
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Example summary",
+			"description": "Example description",
+		},
+	},
+	{
+		Alert: "ExistingAlert",
+		Expr:  intstr.FromString("up == 0"),
+		Labels: map[string]string{
+			"severity": "critical",
+		},
+		Annotations: map[string]string{
+			"summary": "Existing summary",
+		},
+	},
+}
+
+var _ = Describe("Runbooks", func() {
+	var runbooksDir string
+
+	BeforeEach(func() {
+		runbooksDir = filepath.Join(GinkgoT().TempDir(), "runbooks")
+	})
+
+	It("should create a runbook skeleton for each alert", func() {
+		created, err := docs.GenerateRunbooks(alerts, runbooksDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created).To(ConsistOf(
+			filepath.Join(runbooksDir, "ExampleAlert.md"),
+			filepath.Join(runbooksDir, "ExistingAlert.md"),
+		))
+
+		runbook, err := os.ReadFile(filepath.Join(runbooksDir, "ExampleAlert.md"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(runbook)).To(HavePrefix("# ExampleAlert\n\n## Meaning\n\nExample description\n"))
+		Expect(string(runbook)).To(ContainSubstring("## Impact"))
+		Expect(string(runbook)).To(ContainSubstring("## Diagnosis"))
+		Expect(string(runbook)).To(ContainSubstring("## Mitigation"))
+
+		runbook, err = os.ReadFile(filepath.Join(runbooksDir, "ExistingAlert.md"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(runbook)).To(ContainSubstring("## Meaning\n\nExisting summary\n"))
+	})
+
+	It("should not overwrite existing runbooks", func() {
+		Expect(os.MkdirAll(runbooksDir, 0o755)).To(Succeed())
+		existing := filepath.Join(runbooksDir, "ExistingAlert.md")
+		Expect(os.WriteFile(existing, []byte("# ExistingAlert\n"), 0o644)).To(Succeed())
+
+		created, err := docs.GenerateRunbooks(alerts, runbooksDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created).To(ConsistOf(filepath.Join(runbooksDir, "ExampleAlert.md")))
+
+		runbook, err := os.ReadFile(existing)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(runbook)).To(Equal("# ExistingAlert\n"))
+	})
+
+	It("should use a custom template", func() {
+		created, err := docs.GenerateRunbooksWithCustomTemplate(alerts[:1], runbooksDir, "custom {{.Name}}")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created).To(HaveLen(1))
+
+		runbook, err := os.ReadFile(created[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(runbook)).To(Equal("custom ExampleAlert"))
+	})
+
+	It("should return an error for an invalid template", func() {
+		_, err := docs.GenerateRunbooksWithCustomTemplate(alerts, runbooksDir, "{{ .Name ")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,281 @@
+package manifests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+)
+
+const (
+	prometheusRuleFile = "prometheus-rule.yaml"
+	rbacFile           = "rbac.yaml"
+	serviceMonitorFile = "service-monitor.yaml"
+)
+
+// Options holds the configuration of the generated observability bundle.
+type Options struct {
+	// Name is used as the name, or name prefix, of every generated object.
+	Name string
+
+	// Namespace where the objects are deployed. In Helm charts, it is the
+	// default value of the namespace value.
+	Namespace string
+
+	// Labels are set on every generated object. In Helm charts, they are the
+	// default value of the labels value.
+	Labels map[string]string
+
+	// RBAC configures the generated RBAC objects. Its NamePrefix, Namespaces
+	// and Labels are set from the fields above.
+	RBAC operatorrules.RBACOptions
+
+	// ServiceMonitor configures the generated ServiceMonitor.
+	ServiceMonitor operatorrules.MonitorOptions
+}
+
+type manifestFile struct {
+	name    string
+	objects []runtime.Object
+}
+
+// WriteKustomize writes the PrometheusRule, RBAC objects and ServiceMonitor
+// built from the registry as a kustomize layout in dir. The objects and their
+// kustomization.yaml are written to dir/base, and dir/overlays/default holds
+// an overlay setting the namespace and labels.
+func WriteKustomize(dir string, registry *operatorrules.Registry, opts Options) error {
+	files, err := buildManifestFiles(registry, opts)
+	if err != nil {
+		return err
+	}
+
+	baseDir := filepath.Join(dir, "base")
+	var resources []string
+
+	for _, file := range files {
+		content, err := marshalObjects(file.objects, nil)
+		if err != nil {
+			return err
+		}
+
+		if err := writeFile(filepath.Join(baseDir, file.name), content); err != nil {
+			return err
+		}
+		resources = append(resources, file.name)
+	}
+
+	base := map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  resources,
+	}
+	if err := writeYAML(filepath.Join(baseDir, "kustomization.yaml"), base); err != nil {
+		return err
+	}
+
+	overlay := map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"namespace":  opts.Namespace,
+		"resources":  []string{"../../base"},
+	}
+	if len(opts.Labels) != 0 {
+		overlay["labels"] = []map[string]interface{}{
+			{"pairs": opts.Labels, "includeSelectors": false},
+		}
+	}
+
+	return writeYAML(filepath.Join(dir, "overlays", "default", "kustomization.yaml"), overlay)
+}
+
+// WriteHelmChart writes the PrometheusRule, RBAC objects and ServiceMonitor
+// built from the registry as a Helm chart in dir. The namespace and labels of
+// the objects are parameterized by the namespace and labels values, whose
+// defaults are written to dir/values.yaml.
+func WriteHelmChart(dir string, registry *operatorrules.Registry, opts Options) error {
+	files, err := buildManifestFiles(registry, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		content, err := marshalObjects(file.objects, helmTemplateMetadata)
+		if err != nil {
+			return err
+		}
+
+		if err := writeFile(filepath.Join(dir, "templates", file.name), content); err != nil {
+			return err
+		}
+	}
+
+	chart := map[string]interface{}{
+		"apiVersion":  "v2",
+		"name":        opts.Name + "-observability",
+		"description": "Observability assets of " + opts.Name,
+		"type":        "application",
+		"version":     "0.1.0",
+	}
+	if err := writeYAML(filepath.Join(dir, "Chart.yaml"), chart); err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
+		"namespace": opts.Namespace,
+		"labels":    opts.Labels,
+	}
+
+	return writeYAML(filepath.Join(dir, "values.yaml"), values)
+}
+
+func buildManifestFiles(registry *operatorrules.Registry, opts Options) ([]manifestFile, error) {
+	var files []manifestFile
+
+	prometheusRule, err := registry.BuildPrometheusRule(opts.Name+"-prometheus-rules", opts.Namespace, opts.Labels)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, manifestFile{name: prometheusRuleFile, objects: []runtime.Object{prometheusRule}})
+
+	rbacOpts := opts.RBAC
+	rbacOpts.NamePrefix = opts.Name
+	rbacOpts.Namespaces = []string{opts.Namespace}
+	rbacOpts.Labels = opts.Labels
+
+	rbac := operatorrules.BuildRBAC(rbacOpts)
+	var rbacObjects []runtime.Object
+	for _, o := range rbac.ClusterRoles {
+		rbacObjects = append(rbacObjects, o)
+	}
+	for _, o := range rbac.ClusterRoleBindings {
+		rbacObjects = append(rbacObjects, o)
+	}
+	for _, o := range rbac.Roles {
+		rbacObjects = append(rbacObjects, o)
+	}
+	for _, o := range rbac.RoleBindings {
+		rbacObjects = append(rbacObjects, o)
+	}
+	files = append(files, manifestFile{name: rbacFile, objects: rbacObjects})
+
+	serviceMonitor := operatorrules.BuildServiceMonitor(opts.Name, opts.Namespace, opts.Labels, opts.ServiceMonitor)
+	files = append(files, manifestFile{name: serviceMonitorFile, objects: []runtime.Object{serviceMonitor}})
+
+	return files, nil
+}
+
+// marshalObjects returns the objects as a multi-document YAML. When set,
+// templateMetadata is called with the YAML of each object to adapt it.
+func marshalObjects(objects []runtime.Object, templateMetadata func(obj map[string]interface{}) (string, error)) ([]byte, error) {
+	var buf bytes.Buffer
+
+	for i, object := range objects {
+		obj, err := toUnstructured(object)
+		if err != nil {
+			return nil, err
+		}
+
+		var content string
+		if templateMetadata != nil {
+			content, err = templateMetadata(obj)
+		} else {
+			content, err = marshalYAML(obj)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.WriteString(content)
+	}
+
+	return buf.Bytes(), nil
+}
+
+const (
+	namespacePlaceholder = "__NAMESPACE__"
+	labelsPlaceholder    = "__LABELS__"
+)
+
+// helmTemplateMetadata replaces the namespace and labels of the object by
+// references to the chart values and escapes any existing template action,
+// such as the ones in alert annotations, so they are not rendered by Helm.
+func helmTemplateMetadata(obj map[string]interface{}) (string, error) {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if metadata == nil {
+		return "", fmt.Errorf("object has no metadata")
+	}
+
+	if _, ok := metadata["namespace"]; ok {
+		metadata["namespace"] = namespacePlaceholder
+	}
+	metadata["labels"] = labelsPlaceholder
+
+	content, err := marshalYAML(obj)
+	if err != nil {
+		return "", err
+	}
+
+	content = strings.NewReplacer(`{{`, `{{ "{{" }}`, `}}`, `{{ "}}" }}`).Replace(content)
+	content = strings.Replace(content,
+		"namespace: "+namespacePlaceholder,
+		"namespace: {{ .Values.namespace | default .Release.Namespace }}", 1)
+	content = strings.Replace(content,
+		"labels: "+labelsPlaceholder,
+		"labels:\n    {{- toYaml .Values.labels | nindent 4 }}", 1)
+
+	return content, nil
+}
+
+func toUnstructured(object runtime.Object) (map[string]interface{}, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+
+	return obj, nil
+}
+
+func marshalYAML(obj interface{}) (string, error) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func writeYAML(path string, obj interface{}) error {
+	content, err := marshalYAML(obj)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(path, []byte(content))
+}
+
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,120 @@
+package manifests_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	"github.com/machadovilaca/operator-observability/pkg/manifests"
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+)
+
+var _ = Describe("Manifests", func() {
+	var (
+		dir      string
+		registry *operatorrules.Registry
+		opts     manifests.Options
+	)
+
+	readFile := func(path ...string) string {
+		data, err := os.ReadFile(filepath.Join(append([]string{dir}, path...)...))
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+
+		registry = operatorrules.NewRegistry()
+		err := registry.RegisterAlerts([]promv1.Rule{
+			{
+				Alert:       "GuestbookOperatorDown",
+				Expr:        intstr.FromString("number_of_pods == 0"),
+				Labels:      map[string]string{"severity": "critical"},
+				Annotations: map[string]string{"summary": "Operator is down in {{ $labels.namespace }}"},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		opts = manifests.Options{
+			Name:      "guestbook-operator",
+			Namespace: "guestbook",
+			Labels:    map[string]string{"app": "guestbook-operator"},
+			RBAC: operatorrules.RBACOptions{
+				PrometheusSAName:      "prometheus-k8s",
+				PrometheusSANamespace: "monitoring",
+			},
+			ServiceMonitor: operatorrules.MonitorOptions{
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "guestbook-operator"}},
+			},
+		}
+	})
+
+	Context("Kustomize", func() {
+		It("should write the base resources and the default overlay", func() {
+			Expect(manifests.WriteKustomize(dir, registry, opts)).To(Succeed())
+
+			kustomization := map[string]interface{}{}
+			Expect(yaml.Unmarshal([]byte(readFile("base", "kustomization.yaml")), &kustomization)).To(Succeed())
+			Expect(kustomization["resources"]).To(ConsistOf("prometheus-rule.yaml", "rbac.yaml", "service-monitor.yaml"))
+
+			prometheusRule := &promv1.PrometheusRule{}
+			Expect(yaml.UnmarshalStrict([]byte(readFile("base", "prometheus-rule.yaml")), prometheusRule)).To(Succeed())
+			Expect(prometheusRule.Name).To(Equal("guestbook-operator-prometheus-rules"))
+			Expect(prometheusRule.Kind).To(Equal("PrometheusRule"))
+			Expect(prometheusRule.Spec.Groups[0].Rules[0].Annotations).To(HaveKeyWithValue("summary", "Operator is down in {{ $labels.namespace }}"))
+
+			Expect(readFile("base", "rbac.yaml")).To(ContainSubstring("kind: Role\n"))
+			Expect(readFile("base", "rbac.yaml")).To(ContainSubstring("kind: RoleBinding\n"))
+			Expect(readFile("base", "service-monitor.yaml")).To(ContainSubstring("kind: ServiceMonitor\n"))
+
+			overlay := readFile("overlays", "default", "kustomization.yaml")
+			Expect(overlay).To(ContainSubstring("namespace: guestbook\n"))
+			Expect(overlay).To(ContainSubstring("- ../../base\n"))
+			Expect(overlay).To(ContainSubstring("app: guestbook-operator\n"))
+		})
+
+		It("should fail when the registry is empty", func() {
+			err := manifests.WriteKustomize(dir, operatorrules.NewRegistry(), opts)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Helm", func() {
+		It("should write the templates with parameterized namespace and labels", func() {
+			Expect(manifests.WriteHelmChart(dir, registry, opts)).To(Succeed())
+
+			prometheusRule := readFile("templates", "prometheus-rule.yaml")
+			Expect(prometheusRule).To(ContainSubstring("namespace: {{ .Values.namespace | default .Release.Namespace }}\n"))
+			Expect(prometheusRule).To(ContainSubstring("labels:\n    {{- toYaml .Values.labels | nindent 4 }}\n"))
+			Expect(prometheusRule).To(ContainSubstring(`Operator is down in {{ "{{" }} $labels.namespace {{ "}}" }}`))
+
+			Expect(readFile("templates", "rbac.yaml")).To(ContainSubstring("kind: RoleBinding\n"))
+			Expect(readFile("templates", "service-monitor.yaml")).To(ContainSubstring("kind: ServiceMonitor\n"))
+
+			values := map[string]interface{}{}
+			Expect(yaml.Unmarshal([]byte(readFile("values.yaml")), &values)).To(Succeed())
+			Expect(values).To(HaveKeyWithValue("namespace", "guestbook"))
+			Expect(values).To(HaveKeyWithValue("labels", HaveKeyWithValue("app", "guestbook-operator")))
+
+			Expect(readFile("Chart.yaml")).To(ContainSubstring("name: guestbook-operator-observability\n"))
+		})
+
+		It("should not template the namespace of cluster scoped objects", func() {
+			opts.RBAC.ClusterWide = true
+			Expect(manifests.WriteHelmChart(dir, registry, opts)).To(Succeed())
+
+			rbac := readFile("templates", "rbac.yaml")
+			Expect(rbac).To(ContainSubstring("kind: ClusterRole\n"))
+			Expect(rbac).ToNot(ContainSubstring(".Values.namespace"))
+		})
+	})
+})
@@ -40,7 +40,7 @@ func (c Collector) Collect(ch chan<- prometheus.Metric) {
 	collectedMetrics := c.CollectCallback()
 
 	for _, cr := range collectedMetrics {
-		metric, ok := operatorRegistry.registeredCollectorMetrics[cr.Metric.GetOpts().Name]
+		metric, ok := operatorRegistry.collectorMetric(cr.Metric.GetOpts().Name)
 		if !ok {
 			log.Printf("metric %s not found in registry", cr.Metric.GetOpts().Name)
 			continue
@@ -89,7 +89,12 @@ func collectValue(ch chan<- prometheus.Metric, metric Metric, cr CollectorResult
 		labels,
 	)
 
-	cm, err := prometheus.NewConstMetric(desc, mType, cr.Value, cr.Labels...)
+	lvs, err := newLabelValuesChecker(metric.GetOpts(), metric.GetOpts().labels).check(cr.Labels)
+	if err != nil {
+		return err
+	}
+
+	cm, err := prometheus.NewConstMetric(desc, mType, cr.Value, lvs...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,79 @@
+package operatormetrics_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("CommonConstLabels", func() {
+	var metricsFetcher testutil.MetricsFetcher
+
+	BeforeEach(func() {
+		metricsFetcher = useTestRegistry()
+	})
+
+	It("should add the common const labels of the builder to metrics and collector metrics", func() {
+		optsBuilder := operatormetrics.NewOptsBuilder(operatormetrics.WithCommonConstLabels(map[string]string{
+			"kubernetes_operator_part_of":   "guestbook",
+			"kubernetes_operator_component": "guestbook-operator",
+		}))
+
+		gaugeVec := operatormetrics.NewGaugeVec(optsBuilder.Build(operatormetrics.MetricOpts{
+			Name: "test_common_labels_gauge_vec",
+			Help: "A test common labels gauge vec",
+		}), []string{"pod"})
+		counter := operatormetrics.NewCounter(optsBuilder.Build(operatormetrics.MetricOpts{
+			Name: "test_common_labels_counter",
+			Help: "A test common labels counter",
+			ConstLabels: map[string]string{
+				"kubernetes_operator_component": "guestbook-webhook",
+				"kubernetes_operator_part_of":   "",
+			},
+		}))
+		collectorGauge := operatormetrics.NewGauge(optsBuilder.Build(operatormetrics.MetricOpts{
+			Name: "test_common_labels_collector_gauge",
+			Help: "A test common labels collector gauge",
+		}))
+		collector := operatormetrics.Collector{
+			Metrics: []operatormetrics.Metric{collectorGauge},
+			CollectCallback: func() []operatormetrics.CollectorResult {
+				return []operatormetrics.CollectorResult{{Metric: collectorGauge, Value: 1}}
+			},
+		}
+
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{gaugeVec, counter})).To(Succeed())
+		Expect(operatormetrics.RegisterCollector(collector)).To(Succeed())
+
+		gaugeVec.WithLabelValues("pod-a").Set(1)
+		counter.Inc()
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics).To(testutil.HaveMetric("test_common_labels_gauge_vec").WithLabels(
+			"pod", "pod-a",
+			"kubernetes_operator_part_of", "guestbook",
+			"kubernetes_operator_component", "guestbook-operator",
+		))
+		Expect(metrics).To(testutil.HaveMetric("test_common_labels_collector_gauge").WithLabels(
+			"kubernetes_operator_part_of", "guestbook",
+		))
+		Expect(metrics["test_common_labels_counter"]).To(HaveLen(1))
+		Expect(metrics["test_common_labels_counter"][0].Labels).To(Equal(map[string]string{
+			"kubernetes_operator_component": "guestbook-webhook",
+		}))
+		Expect(gaugeVec.GetOpts().ConstLabels).To(HaveKeyWithValue("kubernetes_operator_part_of", "guestbook"))
+	})
+
+	It("should keep the common const labels it was created with", func() {
+		labels := map[string]string{"kubernetes_operator_part_of": "guestbook"}
+		optsBuilder := operatormetrics.NewOptsBuilder(operatormetrics.WithCommonConstLabels(labels))
+		labels["kubernetes_operator_part_of"] = "changed"
+
+		Expect(optsBuilder.CommonConstLabels()).To(Equal(map[string]string{"kubernetes_operator_part_of": "guestbook"}))
+		Expect(optsBuilder.Build(operatormetrics.MetricOpts{Name: "test"}).ConstLabels).To(
+			Equal(map[string]string{"kubernetes_operator_part_of": "guestbook"}))
+	})
+})
@@ -13,6 +13,8 @@ var _ Metric = &Counter{}
 // NewCounter creates a new Counter. The Counter must be registered with the
 // Prometheus registry through RegisterMetrics.
 func NewCounter(metricOpts MetricOpts) *Counter {
+	metricOpts = metricOpts.WithFullName()
+
 	return &Counter{
 		Counter:    prometheus.NewCounter(prometheus.CounterOpts(convertOpts(metricOpts))),
 		metricOpts: metricOpts,
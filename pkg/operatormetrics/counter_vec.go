@@ -1,23 +1,35 @@
 package operatormetrics
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 type CounterVec struct {
 	prometheus.CounterVec
 
 	metricOpts MetricOpts
+	limiter    *seriesLimiter
+	checker    *labelValuesChecker
 }
 
-var _ Metric = &CounterVec{}
+var (
+	_ Metric    = &CounterVec{}
+	_ seriesVec = &CounterVec{}
+)
 
 // NewCounterVec creates a new CounterVec. The CounterVec must be registered
 // with the Prometheus registry through RegisterMetrics.
 func NewCounterVec(metricOpts MetricOpts, labels []string) *CounterVec {
+	metricOpts = metricOpts.WithFullName()
 	metricOpts.labels = labels
 
 	return &CounterVec{
 		CounterVec: *prometheus.NewCounterVec(prometheus.CounterOpts(convertOpts(metricOpts)), labels),
 		metricOpts: metricOpts,
+		limiter:    newSeriesLimiter(metricOpts, labels),
+		checker:    newLabelValuesChecker(metricOpts, labels),
 	}
 }
 
@@ -36,3 +48,125 @@ func (c *CounterVec) GetBaseType() MetricType {
 func (c *CounterVec) GetCollector() prometheus.Collector {
 	return c.CounterVec
 }
+
+// WithLabelValues works as prometheus.CounterVec.WithLabelValues. Past the MaxSeries
+// limit, new label combinations are handled according to the OverflowPolicy,
+// and values not in AllowedLabelValues according to the LabelValuePolicy.
+func (c *CounterVec) WithLabelValues(lvs ...string) prometheus.Counter {
+	m, err := c.GetMetricWithLabelValues(lvs...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// With works as prometheus.CounterVec.With, with the MaxSeries limit and
+// AllowedLabelValues of WithLabelValues.
+func (c *CounterVec) With(labels prometheus.Labels) prometheus.Counter {
+	m, err := c.GetMetricWith(labels)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// GetMetricWithLabelValues works as prometheus.CounterVec.GetMetricWithLabelValues,
+// with the MaxSeries limit and AllowedLabelValues of WithLabelValues.
+func (c *CounterVec) GetMetricWithLabelValues(lvs ...string) (prometheus.Counter, error) {
+	lvs, err := c.checker.check(lvs)
+	if err != nil {
+		return nil, err
+	}
+
+	lvs, ok := c.limiter.admit(lvs)
+	if !ok {
+		return c.discarded(), nil
+	}
+	return c.CounterVec.GetMetricWithLabelValues(lvs...)
+}
+
+// GetMetricWith works as prometheus.CounterVec.GetMetricWith, with the MaxSeries
+// limit and AllowedLabelValues of WithLabelValues.
+func (c *CounterVec) GetMetricWith(labels prometheus.Labels) (prometheus.Counter, error) {
+	labels, err := c.checker.checkLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, ok := c.limiter.admitLabels(labels)
+	if !ok {
+		return c.discarded(), nil
+	}
+	return c.CounterVec.GetMetricWith(labels)
+}
+
+// DeleteLabelValues works as prometheus.CounterVec.DeleteLabelValues, and frees
+// the series from the MaxSeries limit.
+func (c *CounterVec) DeleteLabelValues(lvs ...string) bool {
+	c.limiter.forget(lvs)
+	return c.CounterVec.DeleteLabelValues(lvs...)
+}
+
+// Delete works as prometheus.CounterVec.Delete, and frees the series from the
+// MaxSeries limit.
+func (c *CounterVec) Delete(labels prometheus.Labels) bool {
+	c.limiter.forgetLabels(labels)
+	return c.CounterVec.Delete(labels)
+}
+
+// DeletePartialMatch works as prometheus.CounterVec.DeletePartialMatch, and frees
+// the series from the MaxSeries limit.
+func (c *CounterVec) DeletePartialMatch(labels prometheus.Labels) int {
+	c.limiter.forgetPartialMatch(labels)
+	return c.CounterVec.DeletePartialMatch(labels)
+}
+
+// Reset works as prometheus.CounterVec.Reset, and frees all series from the
+// MaxSeries limit.
+func (c *CounterVec) Reset() {
+	c.limiter.reset()
+	c.CounterVec.Reset()
+}
+
+// deleteExpiredSeries deletes the series not accessed for longer than the
+// SeriesTTL.
+func (c *CounterVec) deleteExpiredSeries(now time.Time) int {
+	return c.limiter.expire(now, c.CounterVec.DeleteLabelValues)
+}
+
+// discarded returns a metric that is not collected, for the values refused
+// by the MaxSeries limit.
+func (c *CounterVec) discarded() prometheus.Counter {
+	return prometheus.NewCounter(prometheus.CounterOpts(convertOpts(c.metricOpts)))
+}
+
+// CounterVecT is a CounterVec whose labels are declared by the fields of the
+// label set struct L, tagged with LabelTag.
+type CounterVecT[L any] struct {
+	*CounterVec
+	typedLabels[L]
+}
+
+var _ labelsValidator = &CounterVecT[struct{}]{}
+
+// NewCounterVecT creates a new CounterVecT. The label set is validated when the
+// CounterVecT is registered with the Prometheus registry through
+// RegisterMetrics.
+func NewCounterVecT[L any](metricOpts MetricOpts) *CounterVecT[L] {
+	tl := newTypedLabels[L]()
+
+	return &CounterVecT[L]{
+		CounterVec:  NewCounterVec(metricOpts, tl.names),
+		typedLabels: tl,
+	}
+}
+
+// With returns the Counter for the given label set.
+func (c *CounterVecT[L]) With(labels L) prometheus.Counter {
+	return c.CounterVec.WithLabelValues(c.values(labels)...)
+}
+
+// Delete deletes the series of the given label set.
+func (c *CounterVecT[L]) Delete(labels L) bool {
+	return c.CounterVec.DeleteLabelValues(c.values(labels)...)
+}
@@ -13,6 +13,8 @@ var _ Metric = &Gauge{}
 // NewGauge creates a new Gauge. The Gauge must be registered with the
 // Prometheus registry through RegisterMetrics.
 func NewGauge(metricOpts MetricOpts) *Gauge {
+	metricOpts = metricOpts.WithFullName()
+
 	return &Gauge{
 		Gauge:      prometheus.NewGauge(prometheus.GaugeOpts(convertOpts(metricOpts))),
 		metricOpts: metricOpts,
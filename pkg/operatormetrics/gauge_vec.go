@@ -1,23 +1,35 @@
 package operatormetrics
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 type GaugeVec struct {
 	prometheus.GaugeVec
 
 	metricOpts MetricOpts
+	limiter    *seriesLimiter
+	checker    *labelValuesChecker
 }
 
-var _ Metric = &GaugeVec{}
+var (
+	_ Metric    = &GaugeVec{}
+	_ seriesVec = &GaugeVec{}
+)
 
 // NewGaugeVec creates a new GaugeVec. The GaugeVec must be registered
 // with the Prometheus registry through RegisterMetrics.
 func NewGaugeVec(metricOpts MetricOpts, labels []string) *GaugeVec {
+	metricOpts = metricOpts.WithFullName()
 	metricOpts.labels = labels
 
 	return &GaugeVec{
 		GaugeVec:   *prometheus.NewGaugeVec(prometheus.GaugeOpts(convertOpts(metricOpts)), labels),
 		metricOpts: metricOpts,
+		limiter:    newSeriesLimiter(metricOpts, labels),
+		checker:    newLabelValuesChecker(metricOpts, labels),
 	}
 }
 
@@ -36,3 +48,124 @@ func (c *GaugeVec) GetBaseType() MetricType {
 func (c *GaugeVec) GetCollector() prometheus.Collector {
 	return c.GaugeVec
 }
+
+// WithLabelValues works as prometheus.GaugeVec.WithLabelValues. Past the MaxSeries
+// limit, new label combinations are handled according to the OverflowPolicy,
+// and values not in AllowedLabelValues according to the LabelValuePolicy.
+func (c *GaugeVec) WithLabelValues(lvs ...string) prometheus.Gauge {
+	m, err := c.GetMetricWithLabelValues(lvs...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// With works as prometheus.GaugeVec.With, with the MaxSeries limit and
+// AllowedLabelValues of WithLabelValues.
+func (c *GaugeVec) With(labels prometheus.Labels) prometheus.Gauge {
+	m, err := c.GetMetricWith(labels)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// GetMetricWithLabelValues works as prometheus.GaugeVec.GetMetricWithLabelValues,
+// with the MaxSeries limit and AllowedLabelValues of WithLabelValues.
+func (c *GaugeVec) GetMetricWithLabelValues(lvs ...string) (prometheus.Gauge, error) {
+	lvs, err := c.checker.check(lvs)
+	if err != nil {
+		return nil, err
+	}
+
+	lvs, ok := c.limiter.admit(lvs)
+	if !ok {
+		return c.discarded(), nil
+	}
+	return c.GaugeVec.GetMetricWithLabelValues(lvs...)
+}
+
+// GetMetricWith works as prometheus.GaugeVec.GetMetricWith, with the MaxSeries
+// limit and AllowedLabelValues of WithLabelValues.
+func (c *GaugeVec) GetMetricWith(labels prometheus.Labels) (prometheus.Gauge, error) {
+	labels, err := c.checker.checkLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, ok := c.limiter.admitLabels(labels)
+	if !ok {
+		return c.discarded(), nil
+	}
+	return c.GaugeVec.GetMetricWith(labels)
+}
+
+// DeleteLabelValues works as prometheus.GaugeVec.DeleteLabelValues, and frees
+// the series from the MaxSeries limit.
+func (c *GaugeVec) DeleteLabelValues(lvs ...string) bool {
+	c.limiter.forget(lvs)
+	return c.GaugeVec.DeleteLabelValues(lvs...)
+}
+
+// Delete works as prometheus.GaugeVec.Delete, and frees the series from the
+// MaxSeries limit.
+func (c *GaugeVec) Delete(labels prometheus.Labels) bool {
+	c.limiter.forgetLabels(labels)
+	return c.GaugeVec.Delete(labels)
+}
+
+// DeletePartialMatch works as prometheus.GaugeVec.DeletePartialMatch, and frees
+// the series from the MaxSeries limit.
+func (c *GaugeVec) DeletePartialMatch(labels prometheus.Labels) int {
+	c.limiter.forgetPartialMatch(labels)
+	return c.GaugeVec.DeletePartialMatch(labels)
+}
+
+// Reset works as prometheus.GaugeVec.Reset, and frees all series from the
+// MaxSeries limit.
+func (c *GaugeVec) Reset() {
+	c.limiter.reset()
+	c.GaugeVec.Reset()
+}
+
+// deleteExpiredSeries deletes the series not accessed for longer than the
+// SeriesTTL.
+func (c *GaugeVec) deleteExpiredSeries(now time.Time) int {
+	return c.limiter.expire(now, c.GaugeVec.DeleteLabelValues)
+}
+
+// discarded returns a metric that is not collected, for the values refused
+// by the MaxSeries limit.
+func (c *GaugeVec) discarded() prometheus.Gauge {
+	return prometheus.NewGauge(prometheus.GaugeOpts(convertOpts(c.metricOpts)))
+}
+
+// GaugeVecT is a GaugeVec whose labels are declared by the fields of the label
+// set struct L, tagged with LabelTag.
+type GaugeVecT[L any] struct {
+	*GaugeVec
+	typedLabels[L]
+}
+
+var _ labelsValidator = &GaugeVecT[struct{}]{}
+
+// NewGaugeVecT creates a new GaugeVecT. The label set is validated when the
+// GaugeVecT is registered with the Prometheus registry through RegisterMetrics.
+func NewGaugeVecT[L any](metricOpts MetricOpts) *GaugeVecT[L] {
+	tl := newTypedLabels[L]()
+
+	return &GaugeVecT[L]{
+		GaugeVec:    NewGaugeVec(metricOpts, tl.names),
+		typedLabels: tl,
+	}
+}
+
+// With returns the Gauge for the given label set.
+func (c *GaugeVecT[L]) With(labels L) prometheus.Gauge {
+	return c.GaugeVec.WithLabelValues(c.values(labels)...)
+}
+
+// Delete deletes the series of the given label set.
+func (c *GaugeVecT[L]) Delete(labels L) bool {
+	return c.GaugeVec.DeleteLabelValues(c.values(labels)...)
+}
@@ -16,6 +16,8 @@ var _ Metric = &Histogram{}
 // NewHistogram creates a new Histogram. The Histogram must be registered with the
 // Prometheus registry through RegisterMetrics.
 func NewHistogram(metricOpts MetricOpts, histogramOpts prometheus.HistogramOpts) *Histogram {
+	metricOpts = metricOpts.WithFullName()
+
 	return &Histogram{
 		Histogram:     prometheus.NewHistogram(makePrometheusHistogramOpts(metricOpts, histogramOpts)),
 		metricOpts:    metricOpts,
@@ -1,23 +1,37 @@
 package operatormetrics
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 type HistogramVec struct {
 	prometheus.HistogramVec
 
 	metricOpts    MetricOpts
 	histogramOpts prometheus.HistogramOpts
+	limiter       *seriesLimiter
+	checker       *labelValuesChecker
 }
 
-var _ Metric = &HistogramVec{}
+var (
+	_ Metric    = &HistogramVec{}
+	_ seriesVec = &HistogramVec{}
+)
 
 // NewHistogramVec creates a new HistogramVec. The HistogramVec must be
 // registered with the Prometheus registry through RegisterMetrics.
 func NewHistogramVec(metricOpts MetricOpts, histogramOpts prometheus.HistogramOpts, labels []string) *HistogramVec {
+	metricOpts = metricOpts.WithFullName()
+	metricOpts.labels = labels
+
 	return &HistogramVec{
 		HistogramVec:  *prometheus.NewHistogramVec(makePrometheusHistogramOpts(metricOpts, histogramOpts), labels),
 		metricOpts:    metricOpts,
 		histogramOpts: histogramOpts,
+		limiter:       newSeriesLimiter(metricOpts, labels),
+		checker:       newLabelValuesChecker(metricOpts, labels),
 	}
 }
 
@@ -40,3 +54,125 @@ func (c *HistogramVec) GetBaseType() MetricType {
 func (c *HistogramVec) GetCollector() prometheus.Collector {
 	return c.HistogramVec
 }
+
+// WithLabelValues works as prometheus.HistogramVec.WithLabelValues. Past the MaxSeries
+// limit, new label combinations are handled according to the OverflowPolicy,
+// and values not in AllowedLabelValues according to the LabelValuePolicy.
+func (c *HistogramVec) WithLabelValues(lvs ...string) prometheus.Observer {
+	m, err := c.GetMetricWithLabelValues(lvs...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// With works as prometheus.HistogramVec.With, with the MaxSeries limit and
+// AllowedLabelValues of WithLabelValues.
+func (c *HistogramVec) With(labels prometheus.Labels) prometheus.Observer {
+	m, err := c.GetMetricWith(labels)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// GetMetricWithLabelValues works as prometheus.HistogramVec.GetMetricWithLabelValues,
+// with the MaxSeries limit and AllowedLabelValues of WithLabelValues.
+func (c *HistogramVec) GetMetricWithLabelValues(lvs ...string) (prometheus.Observer, error) {
+	lvs, err := c.checker.check(lvs)
+	if err != nil {
+		return nil, err
+	}
+
+	lvs, ok := c.limiter.admit(lvs)
+	if !ok {
+		return c.discarded(), nil
+	}
+	return c.HistogramVec.GetMetricWithLabelValues(lvs...)
+}
+
+// GetMetricWith works as prometheus.HistogramVec.GetMetricWith, with the MaxSeries
+// limit and AllowedLabelValues of WithLabelValues.
+func (c *HistogramVec) GetMetricWith(labels prometheus.Labels) (prometheus.Observer, error) {
+	labels, err := c.checker.checkLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, ok := c.limiter.admitLabels(labels)
+	if !ok {
+		return c.discarded(), nil
+	}
+	return c.HistogramVec.GetMetricWith(labels)
+}
+
+// DeleteLabelValues works as prometheus.HistogramVec.DeleteLabelValues, and frees
+// the series from the MaxSeries limit.
+func (c *HistogramVec) DeleteLabelValues(lvs ...string) bool {
+	c.limiter.forget(lvs)
+	return c.HistogramVec.DeleteLabelValues(lvs...)
+}
+
+// Delete works as prometheus.HistogramVec.Delete, and frees the series from the
+// MaxSeries limit.
+func (c *HistogramVec) Delete(labels prometheus.Labels) bool {
+	c.limiter.forgetLabels(labels)
+	return c.HistogramVec.Delete(labels)
+}
+
+// DeletePartialMatch works as prometheus.HistogramVec.DeletePartialMatch, and frees
+// the series from the MaxSeries limit.
+func (c *HistogramVec) DeletePartialMatch(labels prometheus.Labels) int {
+	c.limiter.forgetPartialMatch(labels)
+	return c.HistogramVec.DeletePartialMatch(labels)
+}
+
+// Reset works as prometheus.HistogramVec.Reset, and frees all series from the
+// MaxSeries limit.
+func (c *HistogramVec) Reset() {
+	c.limiter.reset()
+	c.HistogramVec.Reset()
+}
+
+// deleteExpiredSeries deletes the series not accessed for longer than the
+// SeriesTTL.
+func (c *HistogramVec) deleteExpiredSeries(now time.Time) int {
+	return c.limiter.expire(now, c.HistogramVec.DeleteLabelValues)
+}
+
+// discarded returns a metric that is not collected, for the values refused
+// by the MaxSeries limit.
+func (c *HistogramVec) discarded() prometheus.Observer {
+	return prometheus.NewHistogram(makePrometheusHistogramOpts(c.metricOpts, c.histogramOpts))
+}
+
+// HistogramVecT is a HistogramVec whose labels are declared by the fields of
+// the label set struct L, tagged with LabelTag.
+type HistogramVecT[L any] struct {
+	*HistogramVec
+	typedLabels[L]
+}
+
+var _ labelsValidator = &HistogramVecT[struct{}]{}
+
+// NewHistogramVecT creates a new HistogramVecT. The label set is validated when
+// the HistogramVecT is registered with the Prometheus registry through
+// RegisterMetrics.
+func NewHistogramVecT[L any](metricOpts MetricOpts, histogramOpts prometheus.HistogramOpts) *HistogramVecT[L] {
+	tl := newTypedLabels[L]()
+
+	return &HistogramVecT[L]{
+		HistogramVec: NewHistogramVec(metricOpts, histogramOpts, tl.names),
+		typedLabels:  tl,
+	}
+}
+
+// With returns the Observer for the given label set.
+func (c *HistogramVecT[L]) With(labels L) prometheus.Observer {
+	return c.HistogramVec.WithLabelValues(c.values(labels)...)
+}
+
+// Delete deletes the series of the given label set.
+func (c *HistogramVecT[L]) Delete(labels L) bool {
+	return c.HistogramVec.DeleteLabelValues(c.values(labels)...)
+}
@@ -0,0 +1,118 @@
+package operatormetrics
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LabelValuePolicy defines what happens to the label values of a Vec metric
+// not in its AllowedLabelValues.
+type LabelValuePolicy string
+
+const (
+	// LabelValueStrict rejects values not in AllowedLabelValues.
+	// WithLabelValues and With panic, and GetMetricWithLabelValues and
+	// GetMetricWith return an error.
+	LabelValueStrict LabelValuePolicy = "Strict"
+	// LabelValueCoerce replaces values not in AllowedLabelValues with
+	// OtherLabelValue.
+	LabelValueCoerce LabelValuePolicy = "Coerce"
+)
+
+// OtherLabelValue is the label value replacing the values not in
+// AllowedLabelValues with the LabelValueCoerce policy.
+const OtherLabelValue = "other"
+
+// labelValuesChecker enforces the AllowedLabelValues of a Vec metric.
+type labelValuesChecker struct {
+	name    string
+	labels  []string
+	allowed map[string][]string
+	policy  LabelValuePolicy
+}
+
+func newLabelValuesChecker(metricOpts MetricOpts, labels []string) *labelValuesChecker {
+	if len(metricOpts.AllowedLabelValues) == 0 {
+		return nil
+	}
+
+	return &labelValuesChecker{
+		name:    metricOpts.Name,
+		labels:  labels,
+		allowed: metricOpts.AllowedLabelValues,
+		policy:  metricOpts.LabelValuePolicy,
+	}
+}
+
+// check returns the label values to set the value on, or an error when a
+// value is not allowed with the LabelValueStrict policy.
+func (c *labelValuesChecker) check(lvs []string) ([]string, error) {
+	if c == nil || len(lvs) != len(c.labels) {
+		return lvs, nil
+	}
+
+	var checked []string
+	for i, label := range c.labels {
+		value, err := c.checkValue(label, lvs[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if value != lvs[i] {
+			if checked == nil {
+				checked = append([]string{}, lvs...)
+			}
+			checked[i] = value
+		}
+	}
+
+	if checked == nil {
+		return lvs, nil
+	}
+	return checked, nil
+}
+
+// checkLabels is like check for labels given by name.
+func (c *labelValuesChecker) checkLabels(labels prometheus.Labels) (prometheus.Labels, error) {
+	if c == nil {
+		return labels, nil
+	}
+
+	var checked prometheus.Labels
+	for label, value := range labels {
+		allowedValue, err := c.checkValue(label, value)
+		if err != nil {
+			return nil, err
+		}
+
+		if allowedValue != value {
+			if checked == nil {
+				checked = make(prometheus.Labels, len(labels))
+				for k, v := range labels {
+					checked[k] = v
+				}
+			}
+			checked[label] = allowedValue
+		}
+	}
+
+	if checked == nil {
+		return labels, nil
+	}
+	return checked, nil
+}
+
+func (c *labelValuesChecker) checkValue(label string, value string) (string, error) {
+	allowed, ok := c.allowed[label]
+	if !ok || slices.Contains(allowed, value) {
+		return value, nil
+	}
+
+	if c.policy == LabelValueCoerce {
+		return OtherLabelValue, nil
+	}
+
+	return "", fmt.Errorf("value %q of label %s is not allowed for metric %s, allowed values: %v", value, label, c.name, allowed)
+}
@@ -0,0 +1,83 @@
+package operatormetrics_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("AllowedLabelValues", func() {
+	var metricsFetcher testutil.MetricsFetcher
+
+	BeforeEach(func() {
+		metricsFetcher = useTestRegistry()
+	})
+
+	It("should reject values not allowed with the strict policy", func() {
+		counterVec := operatormetrics.NewCounterVec(operatormetrics.MetricOpts{
+			Name:               "test_strict_counter_vec",
+			Help:               "A test strict counter vec",
+			AllowedLabelValues: map[string][]string{"action": {"create", "delete"}},
+		}, []string{"controller", "action"})
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{counterVec})).To(Succeed())
+
+		counterVec.WithLabelValues("guestbook", "create").Inc()
+
+		_, err := counterVec.GetMetricWithLabelValues("guestbook", "craete")
+		Expect(err).To(MatchError(ContainSubstring(`value "craete" of label action is not allowed for metric test_strict_counter_vec`)))
+		_, err = counterVec.GetMetricWith(prometheus.Labels{"controller": "guestbook", "action": "craete"})
+		Expect(err).To(HaveOccurred())
+		Expect(func() { counterVec.WithLabelValues("guestbook", "craete") }).To(Panic())
+		Expect(func() { counterVec.With(prometheus.Labels{"controller": "guestbook", "action": "craete"}) }).To(Panic())
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics["test_strict_counter_vec"]).To(HaveLen(1))
+	})
+
+	It("should coerce values not allowed with the coerce policy", func() {
+		gaugeVec := operatormetrics.NewGaugeVec(operatormetrics.MetricOpts{
+			Name:               "test_coerced_gauge_vec",
+			Help:               "A test coerced gauge vec",
+			AllowedLabelValues: map[string][]string{"phase": {"Running", "Failed"}},
+			LabelValuePolicy:   operatormetrics.LabelValueCoerce,
+		}, []string{"phase"})
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{gaugeVec})).To(Succeed())
+
+		gaugeVec.WithLabelValues("Running").Set(1)
+		gaugeVec.WithLabelValues("Runing").Set(2)
+		gaugeVec.With(prometheus.Labels{"phase": "Unknown"}).Add(1)
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics["test_coerced_gauge_vec"]).To(HaveLen(2))
+		Expect(metrics).To(testutil.HaveMetric("test_coerced_gauge_vec").WithLabels("phase", "Running").WithValue(1))
+		Expect(metrics).To(testutil.HaveMetric("test_coerced_gauge_vec").
+			WithLabels("phase", operatormetrics.OtherLabelValue).WithValue(3))
+	})
+
+	It("should coerce collector results not allowed with the coerce policy", func() {
+		gaugeVec := operatormetrics.NewGaugeVec(operatormetrics.MetricOpts{
+			Name:               "test_coerced_collector_gauge_vec",
+			Help:               "A test coerced collector gauge vec",
+			AllowedLabelValues: map[string][]string{"phase": {"Running"}},
+			LabelValuePolicy:   operatormetrics.LabelValueCoerce,
+		}, []string{"phase"})
+		collector := operatormetrics.Collector{
+			Metrics: []operatormetrics.Metric{gaugeVec},
+			CollectCallback: func() []operatormetrics.CollectorResult {
+				return []operatormetrics.CollectorResult{{Metric: gaugeVec, Labels: []string{"Pending"}, Value: 1}}
+			},
+		}
+		Expect(operatormetrics.RegisterCollector(collector)).To(Succeed())
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics).To(testutil.HaveMetric("test_coerced_collector_gauge_vec").
+			WithLabels("phase", operatormetrics.OtherLabelValue).WithValue(1))
+	})
+})
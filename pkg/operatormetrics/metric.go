@@ -1,16 +1,107 @@
 package operatormetrics
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 type MetricOpts struct {
-	Name        string
+	Name string
+	// Namespace and Subsystem are prepended to the Name, joined by
+	// underscores, as in Prometheus. The metric constructors compose them into
+	// the Name.
+	Namespace string
+	Subsystem string
+
 	Help        string
 	ConstLabels map[string]string
 	ExtraFields map[string]string
 
+	// MaxSeries limits the number of label combinations of Vec metrics. Zero
+	// means no limit.
+	MaxSeries int
+	// OverflowPolicy defines what happens to the label combinations past
+	// MaxSeries. Defaults to OverflowRefuse.
+	OverflowPolicy OverflowPolicy
+	// SeriesTTL expires the series of Vec metrics not accessed through
+	// WithLabelValues or With for longer than the TTL. Expired series are
+	// deleted by DeleteExpiredSeries or StartSeriesJanitor. Zero means series
+	// never expire.
+	SeriesTTL time.Duration
+
+	// AllowedLabelValues restricts the values of the labels of Vec metrics to
+	// a known set, by label name.
+	AllowedLabelValues map[string][]string
+	// LabelValuePolicy defines what happens to the label values not in
+	// AllowedLabelValues. Defaults to LabelValueStrict.
+	LabelValuePolicy LabelValuePolicy
+
 	labels []string
 }
 
+// FullName returns the metric name composed of the Namespace, Subsystem and
+// Name.
+func (opts MetricOpts) FullName() string {
+	return prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name)
+}
+
+// FullNameWithPrefix works as FullName, with the given prefix prepended. For
+// recording rule names following the level:metric:operations convention, the
+// prefix is prepended to the metric part.
+func (opts MetricOpts) FullNameWithPrefix(prefix string) string {
+	name := opts.FullName()
+	if name == "" {
+		return name
+	}
+
+	if parts := strings.SplitN(name, ":", 3); len(parts) > 1 {
+		parts[1] = prefix + parts[1]
+		return strings.Join(parts, ":")
+	}
+
+	return prefix + name
+}
+
+// WithFullName returns the options with the FullName as Name, and without
+// the Namespace and Subsystem it is composed of.
+func (opts MetricOpts) WithFullName() MetricOpts {
+	opts.Name = opts.FullName()
+	opts.Namespace = ""
+	opts.Subsystem = ""
+	return opts
+}
+
+// MergeConstLabels returns the labels merged with the common labels. The
+// given labels take precedence, and the labels with an empty value are
+// dropped.
+func MergeConstLabels(common, labels map[string]string) map[string]string {
+	if len(common) == 0 {
+		return labels
+	}
+
+	merged := map[string]string{}
+	for k, v := range common {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	for k, v := range merged {
+		if v == "" {
+			delete(merged, k)
+		}
+	}
+
+	return merged
+}
+
+// Labels returns the variable label names of the metric.
+func (opts MetricOpts) Labels() []string {
+	return opts.labels
+}
+
 type Metric interface {
 	GetOpts() MetricOpts
 	GetType() MetricType
@@ -5,9 +5,32 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
 )
 
 func TestOperatormetrics(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Operatormetrics Suite")
 }
+
+// useTestRegistry registers the metrics with a new Prometheus registry until
+// the end of the spec, and returns a fetcher for its metrics.
+func useTestRegistry() testutil.MetricsFetcher {
+	Expect(operatormetrics.CleanRegistry()).To(Succeed())
+
+	registry := prometheus.NewRegistry()
+
+	operatormetrics.Register = registry.Register
+	operatormetrics.Unregister = registry.Unregister
+	DeferCleanup(func() {
+		Expect(operatormetrics.CleanRegistry()).To(Succeed())
+		operatormetrics.Register = prometheus.Register
+		operatormetrics.Unregister = prometheus.Unregister
+	})
+
+	return testutil.NewGathererMetricsFetcher(registry)
+}
@@ -0,0 +1,66 @@
+package operatormetrics
+
+import "maps"
+
+// OptsBuilder builds the MetricOpts of the metrics and collector metrics of an
+// operator, applying the defaults it was created with, such as a name
+// prefix and common labels. It is meant to be created once, e.g. as a package variable, so the
+// defaults are set before any metric is created with it.
+type OptsBuilder struct {
+	namePrefix        string
+	commonConstLabels map[string]string
+}
+
+// OptsBuilderOption configures an OptsBuilder.
+type OptsBuilderOption func(*OptsBuilder)
+
+// WithNamePrefix sets the prefix prepended to the metric names, such as
+// "guestbook_operator_".
+func WithNamePrefix(prefix string) OptsBuilderOption {
+	return func(b *OptsBuilder) {
+		b.namePrefix = prefix
+	}
+}
+
+// WithCommonConstLabels sets the labels added to the ConstLabels of the
+// metrics, such as the labels identifying the operator. Labels set on a metric
+// take precedence, and an empty value drops the label from the metric.
+func WithCommonConstLabels(labels map[string]string) OptsBuilderOption {
+	return func(b *OptsBuilder) {
+		b.commonConstLabels = maps.Clone(labels)
+	}
+}
+
+// NewOptsBuilder creates a new OptsBuilder.
+func NewOptsBuilder(opts ...OptsBuilderOption) *OptsBuilder {
+	b := &OptsBuilder{}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// NamePrefix returns the prefix prepended to the metric names.
+func (b *OptsBuilder) NamePrefix() string {
+	return b.namePrefix
+}
+
+// CommonConstLabels returns a copy of the labels added to the ConstLabels of
+// the metrics.
+func (b *OptsBuilder) CommonConstLabels() map[string]string {
+	return maps.Clone(b.commonConstLabels)
+}
+
+// Build returns the options with the name prefix prepended to the name
+// composed of the Namespace, Subsystem and Name, and the common labels merged
+// into the ConstLabels. The prefix is always prepended, so the Name must not
+// include it.
+func (b *OptsBuilder) Build(opts MetricOpts) MetricOpts {
+	opts.Name = opts.FullNameWithPrefix(b.namePrefix)
+	opts.ConstLabels = MergeConstLabels(b.commonConstLabels, opts.ConstLabels)
+	opts.Namespace = ""
+	opts.Subsystem = ""
+	return opts
+}
@@ -0,0 +1,92 @@
+package operatormetrics_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("OptsBuilder", func() {
+	var metricsFetcher testutil.MetricsFetcher
+
+	BeforeEach(func() {
+		metricsFetcher = useTestRegistry()
+	})
+
+	It("should compose the name from the namespace and subsystem", func() {
+		gauge := operatormetrics.NewGauge(operatormetrics.MetricOpts{
+			Namespace: "guestbook",
+			Subsystem: "operator",
+			Name:      "ready",
+			Help:      "A test composed gauge",
+		})
+		Expect(gauge.GetOpts().Name).To(Equal("guestbook_operator_ready"))
+
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{gauge})).To(Succeed())
+		gauge.Set(1)
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics).To(testutil.HaveMetric("guestbook_operator_ready").WithValue(1))
+	})
+
+	It("should apply the name prefix of the builder to metrics and collector metrics", func() {
+		optsBuilder := operatormetrics.NewOptsBuilder(operatormetrics.WithNamePrefix("guestbook_operator_"))
+		Expect(optsBuilder.NamePrefix()).To(Equal("guestbook_operator_"))
+
+		counterVec := operatormetrics.NewCounterVec(optsBuilder.Build(operatormetrics.MetricOpts{
+			Name: "reconcile_count",
+			Help: "A test prefixed counter vec",
+		}), []string{"action"})
+		prefixedGauge := operatormetrics.NewGauge(optsBuilder.Build(operatormetrics.MetricOpts{
+			Name: "guestbook_operator_up",
+			Help: "A test already prefixed gauge",
+		}))
+		collectorGauge := operatormetrics.NewGauge(optsBuilder.Build(operatormetrics.MetricOpts{
+			Subsystem: "cr",
+			Name:      "count",
+			Help:      "A test prefixed collector gauge",
+		}))
+		collector := operatormetrics.Collector{
+			Metrics: []operatormetrics.Metric{collectorGauge},
+			CollectCallback: func() []operatormetrics.CollectorResult {
+				return []operatormetrics.CollectorResult{{Metric: collectorGauge, Value: 3}}
+			},
+		}
+
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{counterVec, prefixedGauge})).To(Succeed())
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{counterVec})).To(Succeed())
+		Expect(operatormetrics.RegisterCollector(collector)).To(Succeed())
+
+		Expect(counterVec.GetOpts().Name).To(Equal("guestbook_operator_reconcile_count"))
+		Expect(prefixedGauge.GetOpts().Name).To(Equal("guestbook_operator_guestbook_operator_up"))
+		Expect(collectorGauge.GetOpts().Name).To(Equal("guestbook_operator_cr_count"))
+		Expect(collectorGauge.GetOpts().Subsystem).To(BeEmpty())
+
+		counterVec.WithLabelValues("create").Inc()
+		prefixedGauge.Set(1)
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics).To(HaveLen(3))
+		Expect(metrics).To(testutil.HaveMetric("guestbook_operator_reconcile_count").WithLabels("action", "create"))
+		Expect(metrics).To(testutil.HaveMetric("guestbook_operator_guestbook_operator_up").WithValue(1))
+		Expect(metrics).To(testutil.HaveMetric("guestbook_operator_cr_count").WithValue(3))
+	})
+
+	It("should not prefix the series overflow metric", func() {
+		optsBuilder := operatormetrics.NewOptsBuilder(operatormetrics.WithNamePrefix("guestbook_operator_"))
+
+		gaugeVec := operatormetrics.NewGaugeVec(optsBuilder.Build(operatormetrics.MetricOpts{
+			Name:      "limited",
+			Help:      "A test limited gauge vec",
+			MaxSeries: 1,
+		}), []string{"pod"})
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{gaugeVec})).To(Succeed())
+
+		Expect(operatormetrics.SeriesOverflowTotal.GetOpts().Name).To(Equal("operator_metrics_series_overflow_total"))
+		Expect(operatormetrics.ListMetrics()).To(ContainElement(operatormetrics.SeriesOverflowTotal))
+	})
+})
@@ -0,0 +1,244 @@
+package operatormetrics
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OverflowPolicy defines what happens to the label combinations of a Vec
+// metric past its MaxSeries limit.
+type OverflowPolicy string
+
+const (
+	// OverflowRefuse drops the values set for new label combinations past the
+	// limit.
+	OverflowRefuse OverflowPolicy = "Refuse"
+	// OverflowAggregate sets the values of new label combinations past the
+	// limit on a single series whose label values are all OverflowLabelValue.
+	OverflowAggregate OverflowPolicy = "Aggregate"
+)
+
+// OverflowLabelValue is the label value of the series aggregating the label
+// combinations past the limit with the OverflowAggregate policy.
+const OverflowLabelValue = "overflow"
+
+// SeriesOverflowTotal counts the label combinations of Vec metrics refused or
+// aggregated because of their MaxSeries limit. It is registered by
+// RegisterMetrics along with the first metric with a limit. It is created
+// once, when the package is initialized, without the name prefix and common
+// labels of an OptsBuilder.
+var SeriesOverflowTotal = NewCounterVec(
+	MetricOpts{
+		Name: "operator_metrics_series_overflow_total",
+		Help: "Number of label combinations refused or aggregated because the metric reached its series limit",
+	},
+	[]string{"metric"},
+)
+
+// seriesLimiter tracks the label combinations of a Vec metric to enforce its
+// MaxSeries limit and SeriesTTL.
+type seriesLimiter struct {
+	mu     sync.Mutex
+	name   string
+	labels []string
+	max    int
+	policy OverflowPolicy
+	ttl    time.Duration
+	series map[string]*trackedSeries
+}
+
+type trackedSeries struct {
+	lvs      []string
+	lastSeen time.Time
+}
+
+func newSeriesLimiter(metricOpts MetricOpts, labels []string) *seriesLimiter {
+	return &seriesLimiter{
+		name:   metricOpts.Name,
+		labels: labels,
+		max:    metricOpts.MaxSeries,
+		policy: metricOpts.OverflowPolicy,
+		ttl:    metricOpts.SeriesTTL,
+		series: map[string]*trackedSeries{},
+	}
+}
+
+func (l *seriesLimiter) enabled() bool {
+	return l != nil && (l.max > 0 || l.ttl > 0)
+}
+
+// admit returns the label values to set the value on, and false when the
+// value must be dropped.
+func (l *seriesLimiter) admit(lvs []string) ([]string, bool) {
+	if !l.enabled() || len(lvs) != len(l.labels) {
+		return lvs, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := timeNow()
+
+	key := seriesKey(lvs)
+	if ts, ok := l.series[key]; ok {
+		ts.lastSeen = now
+		return lvs, true
+	}
+
+	if l.max <= 0 || len(l.series) < l.max {
+		l.series[key] = &trackedSeries{lvs: append([]string{}, lvs...), lastSeen: now}
+		return lvs, true
+	}
+
+	SeriesOverflowTotal.WithLabelValues(l.name).Inc()
+
+	if l.policy != OverflowAggregate {
+		return nil, false
+	}
+
+	overflow := make([]string, len(lvs))
+	for i := range overflow {
+		overflow[i] = OverflowLabelValue
+	}
+
+	return overflow, true
+}
+
+// admitLabels is like admit for labels given by name. Labels not matching the
+// variable labels are passed through, for the Vec to report the error.
+func (l *seriesLimiter) admitLabels(labels prometheus.Labels) (prometheus.Labels, bool) {
+	if !l.enabled() {
+		return labels, true
+	}
+
+	lvs, ok := l.labelValues(labels)
+	if !ok {
+		return labels, true
+	}
+
+	lvs, ok = l.admit(lvs)
+	if !ok {
+		return nil, false
+	}
+
+	admitted := make(prometheus.Labels, len(lvs))
+	for i, name := range l.labels {
+		admitted[name] = lvs[i]
+	}
+
+	return admitted, true
+}
+
+// forget removes a label combination, so it no longer counts against the
+// limit.
+func (l *seriesLimiter) forget(lvs []string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.series, seriesKey(lvs))
+}
+
+// forgetLabels is like forget for labels given by name.
+func (l *seriesLimiter) forgetLabels(labels prometheus.Labels) {
+	if l == nil {
+		return
+	}
+
+	if lvs, ok := l.labelValues(labels); ok {
+		l.forget(lvs)
+	}
+}
+
+// forgetPartialMatch removes the label combinations matching the given
+// labels.
+func (l *seriesLimiter) forgetPartialMatch(labels prometheus.Labels) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, ts := range l.series {
+		if l.matches(ts.lvs, labels) {
+			delete(l.series, key)
+		}
+	}
+}
+
+// expire removes the label combinations not seen for longer than the
+// SeriesTTL, calling deleteFn for each of them. It returns the number of
+// deleted series.
+func (l *seriesLimiter) expire(now time.Time, deleteFn func(lvs ...string) bool) int {
+	if l == nil || l.ttl <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	deleted := 0
+	for key, ts := range l.series {
+		if now.Sub(ts.lastSeen) <= l.ttl {
+			continue
+		}
+
+		delete(l.series, key)
+		if deleteFn(ts.lvs...) {
+			deleted++
+		}
+	}
+
+	return deleted
+}
+
+// reset removes all label combinations.
+func (l *seriesLimiter) reset() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.series = map[string]*trackedSeries{}
+}
+
+func (l *seriesLimiter) matches(lvs []string, labels prometheus.Labels) bool {
+	for name, value := range labels {
+		i := slices.Index(l.labels, name)
+		if i < 0 || lvs[i] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *seriesLimiter) labelValues(labels prometheus.Labels) ([]string, bool) {
+	if len(labels) != len(l.labels) {
+		return nil, false
+	}
+
+	lvs := make([]string, len(l.labels))
+	for i, name := range l.labels {
+		value, ok := labels[name]
+		if !ok {
+			return nil, false
+		}
+		lvs[i] = value
+	}
+
+	return lvs, true
+}
+
+func seriesKey(lvs []string) string {
+	return strings.Join(lvs, "\xff")
+}
@@ -0,0 +1,110 @@
+package operatormetrics_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("SeriesLimit", func() {
+	var metricsFetcher testutil.MetricsFetcher
+
+	BeforeEach(func() {
+		metricsFetcher = useTestRegistry()
+	})
+
+	overflowCount := func(name string) float64 {
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, mr := range metrics["operator_metrics_series_overflow_total"] {
+			if mr.Labels["metric"] == name {
+				return mr.Value
+			}
+		}
+		return 0
+	}
+
+	It("should refuse new label combinations past the limit", func() {
+		gaugeVec := operatormetrics.NewGaugeVec(operatormetrics.MetricOpts{
+			Name:      "test_limited_gauge_vec",
+			Help:      "A test limited gauge vec",
+			MaxSeries: 2,
+		}, []string{"pod"})
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{gaugeVec})).To(Succeed())
+
+		gaugeVec.WithLabelValues("pod-a").Set(1)
+		gaugeVec.With(prometheus.Labels{"pod": "pod-b"}).Set(2)
+		gaugeVec.WithLabelValues("pod-c").Set(3)
+		gaugeVec.WithLabelValues("pod-a").Set(4)
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics["test_limited_gauge_vec"]).To(HaveLen(2))
+		Expect(metrics).To(testutil.HaveMetric("test_limited_gauge_vec").WithLabels("pod", "pod-a").WithValue(4))
+		Expect(metrics).ToNot(testutil.HaveMetric("test_limited_gauge_vec").WithLabels("pod", "pod-c"))
+		Expect(overflowCount("test_limited_gauge_vec")).To(Equal(1.0))
+	})
+
+	It("should aggregate new label combinations past the limit", func() {
+		counterVec := operatormetrics.NewCounterVec(operatormetrics.MetricOpts{
+			Name:           "test_aggregated_counter_vec",
+			Help:           "A test aggregated counter vec",
+			MaxSeries:      1,
+			OverflowPolicy: operatormetrics.OverflowAggregate,
+		}, []string{"namespace", "name"})
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{counterVec})).To(Succeed())
+
+		counterVec.WithLabelValues("default", "a").Inc()
+		counterVec.WithLabelValues("default", "b").Inc()
+		counterVec.With(prometheus.Labels{"namespace": "default", "name": "c"}).Inc()
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics["test_aggregated_counter_vec"]).To(HaveLen(2))
+		Expect(metrics).To(testutil.HaveMetric("test_aggregated_counter_vec").
+			WithLabels("namespace", operatormetrics.OverflowLabelValue, "name", operatormetrics.OverflowLabelValue).
+			WithValue(2))
+		Expect(overflowCount("test_aggregated_counter_vec")).To(Equal(2.0))
+	})
+
+	It("should free deleted series from the limit", func() {
+		histogramVec := operatormetrics.NewHistogramVec(operatormetrics.MetricOpts{
+			Name:      "test_limited_histogram_vec",
+			Help:      "A test limited histogram vec",
+			MaxSeries: 1,
+		}, prometheus.HistogramOpts{}, []string{"pod"})
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{histogramVec})).To(Succeed())
+
+		histogramVec.WithLabelValues("pod-a").Observe(1)
+		Expect(histogramVec.DeleteLabelValues("pod-a")).To(BeTrue())
+		histogramVec.WithLabelValues("pod-b").Observe(1)
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics["test_limited_histogram_vec_count"]).To(HaveLen(1))
+		Expect(metrics["test_limited_histogram_vec_count"][0].Labels).To(HaveKeyWithValue("pod", "pod-b"))
+		Expect(overflowCount("test_limited_histogram_vec")).To(BeZero())
+	})
+
+	It("should not limit metrics without MaxSeries", func() {
+		summaryVec := operatormetrics.NewSummaryVec(operatormetrics.MetricOpts{
+			Name: "test_unlimited_summary_vec",
+			Help: "A test unlimited summary vec",
+		}, prometheus.SummaryOpts{}, []string{"pod"})
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{summaryVec})).To(Succeed())
+
+		for _, pod := range []string{"pod-a", "pod-b", "pod-c"} {
+			summaryVec.WithLabelValues(pod).Observe(1)
+		}
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics["test_unlimited_summary_vec_count"]).To(HaveLen(3))
+		Expect(metrics).ToNot(HaveKey("operator_metrics_series_overflow_total"))
+	})
+})
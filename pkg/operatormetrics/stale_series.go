@@ -0,0 +1,70 @@
+package operatormetrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var timeNow = time.Now
+
+// seriesVec is implemented by the Vec metrics, to delete their series across
+// the registry.
+type seriesVec interface {
+	DeletePartialMatch(labels prometheus.Labels) int
+	deleteExpiredSeries(now time.Time) int
+}
+
+// DeleteOwnerSeries deletes the series matching all the given owner labels,
+// such as the namespace and name of a deleted custom resource, from every
+// registered Vec metric. Vec metrics without all the owner labels are left
+// untouched. It returns the number of deleted series.
+func DeleteOwnerSeries(owner prometheus.Labels) int {
+	if len(owner) == 0 {
+		return 0
+	}
+
+	deleted := 0
+	for _, metric := range operatorRegistry.metrics() {
+		if vec, ok := metric.(seriesVec); ok {
+			deleted += vec.DeletePartialMatch(owner)
+		}
+	}
+
+	return deleted
+}
+
+// DeleteExpiredSeries deletes, from every registered Vec metric with a
+// SeriesTTL, the series not accessed for longer than their TTL. It returns the
+// number of deleted series.
+func DeleteExpiredSeries() int {
+	now := timeNow()
+
+	deleted := 0
+	for _, metric := range operatorRegistry.metrics() {
+		if vec, ok := metric.(seriesVec); ok {
+			deleted += vec.deleteExpiredSeries(now)
+		}
+	}
+
+	return deleted
+}
+
+// StartSeriesJanitor calls DeleteExpiredSeries at every interval, until the
+// context is done. It must be started after the metrics are registered.
+func StartSeriesJanitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				DeleteExpiredSeries()
+			}
+		}
+	}()
+}
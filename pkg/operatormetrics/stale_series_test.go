@@ -0,0 +1,115 @@
+package operatormetrics_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("StaleSeries", func() {
+	var metricsFetcher testutil.MetricsFetcher
+
+	BeforeEach(func() {
+		metricsFetcher = useTestRegistry()
+	})
+
+	It("should delete the series of an owner across all registered Vec metrics", func() {
+		gaugeVec := operatormetrics.NewGaugeVec(operatormetrics.MetricOpts{
+			Name: "test_owned_gauge_vec",
+			Help: "A test owned gauge vec",
+		}, []string{"namespace", "name"})
+		counterVec := operatormetrics.NewCounterVec(operatormetrics.MetricOpts{
+			Name: "test_owned_counter_vec",
+			Help: "A test owned counter vec",
+		}, []string{"namespace", "name", "phase"})
+		histogramVec := operatormetrics.NewHistogramVec(operatormetrics.MetricOpts{
+			Name: "test_unowned_histogram_vec",
+			Help: "A test unowned histogram vec",
+		}, prometheus.HistogramOpts{}, []string{"namespace"})
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{gaugeVec, counterVec, histogramVec})).To(Succeed())
+
+		gaugeVec.WithLabelValues("default", "cr-a").Set(1)
+		gaugeVec.WithLabelValues("default", "cr-b").Set(1)
+		counterVec.WithLabelValues("default", "cr-a", "Running").Inc()
+		counterVec.WithLabelValues("default", "cr-a", "Failed").Inc()
+		histogramVec.WithLabelValues("default").Observe(1)
+
+		Expect(operatormetrics.DeleteOwnerSeries(prometheus.Labels{"namespace": "default", "name": "cr-a"})).To(Equal(3))
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics["test_owned_gauge_vec"]).To(HaveLen(1))
+		Expect(metrics).To(testutil.HaveMetric("test_owned_gauge_vec").WithLabels("name", "cr-b"))
+		Expect(metrics).ToNot(HaveKey("test_owned_counter_vec"))
+		Expect(metrics["test_unowned_histogram_vec_count"]).To(HaveLen(1))
+	})
+
+	It("should delete the series not accessed for longer than the TTL", func() {
+		gaugeVec := operatormetrics.NewGaugeVec(operatormetrics.MetricOpts{
+			Name:      "test_expiring_gauge_vec",
+			Help:      "A test expiring gauge vec",
+			SeriesTTL: 50 * time.Millisecond,
+		}, []string{"pod"})
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{gaugeVec})).To(Succeed())
+
+		gaugeVec.WithLabelValues("pod-a").Set(1)
+		gaugeVec.WithLabelValues("pod-b").Set(1)
+		time.Sleep(100 * time.Millisecond)
+		gaugeVec.With(prometheus.Labels{"pod": "pod-b"}).Set(2)
+
+		Expect(operatormetrics.DeleteExpiredSeries()).To(Equal(1))
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics["test_expiring_gauge_vec"]).To(HaveLen(1))
+		Expect(metrics).To(testutil.HaveMetric("test_expiring_gauge_vec").WithLabels("pod", "pod-b").WithValue(2))
+	})
+
+	It("should delete the expired series periodically with the janitor", func() {
+		summaryVec := operatormetrics.NewSummaryVec(operatormetrics.MetricOpts{
+			Name:      "test_expiring_summary_vec",
+			Help:      "A test expiring summary vec",
+			SeriesTTL: 20 * time.Millisecond,
+		}, prometheus.SummaryOpts{}, []string{"pod"})
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{summaryVec})).To(Succeed())
+
+		summaryVec.WithLabelValues("pod-a").Observe(1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		operatormetrics.StartSeriesJanitor(ctx, 10*time.Millisecond)
+
+		Eventually(func() (map[string][]testutil.MetricResult, error) {
+			return metricsFetcher.Run()
+		}).ShouldNot(HaveKey("test_expiring_summary_vec"))
+	})
+
+	It("should delete series while metrics are being registered", func() {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				operatormetrics.DeleteOwnerSeries(prometheus.Labels{"namespace": "default"})
+				operatormetrics.DeleteExpiredSeries()
+			}
+		}()
+
+		for i := 0; i < 20; i++ {
+			gaugeVec := operatormetrics.NewGaugeVec(operatormetrics.MetricOpts{
+				Name: fmt.Sprintf("test_concurrent_gauge_vec_%d", i),
+				Help: "A test concurrent gauge vec",
+			}, []string{"namespace"})
+			Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{gaugeVec})).To(Succeed())
+		}
+
+		Eventually(done).Should(BeClosed())
+	})
+})
@@ -16,6 +16,8 @@ var _ Metric = &Summary{}
 // NewSummary creates a new Summary. The Summary must be registered with the
 // Prometheus registry through RegisterMetrics.
 func NewSummary(metricOpts MetricOpts, summaryOpts prometheus.SummaryOpts) *Summary {
+	metricOpts = metricOpts.WithFullName()
+
 	return &Summary{
 		Summary:     prometheus.NewSummary(makePrometheusSummaryOpts(metricOpts, summaryOpts)),
 		metricOpts:  metricOpts,
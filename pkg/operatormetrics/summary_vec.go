@@ -1,23 +1,37 @@
 package operatormetrics
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 type SummaryVec struct {
 	prometheus.SummaryVec
 
 	metricOpts  MetricOpts
 	summaryOpts prometheus.SummaryOpts
+	limiter     *seriesLimiter
+	checker     *labelValuesChecker
 }
 
-var _ Metric = &SummaryVec{}
+var (
+	_ Metric    = &SummaryVec{}
+	_ seriesVec = &SummaryVec{}
+)
 
 // NewSummaryVec creates a new SummaryVec. The SummaryVec must be
 // registered with the Prometheus registry through RegisterMetrics.
 func NewSummaryVec(metricOpts MetricOpts, summaryOpts prometheus.SummaryOpts, labels []string) *SummaryVec {
+	metricOpts = metricOpts.WithFullName()
+	metricOpts.labels = labels
+
 	return &SummaryVec{
 		SummaryVec:  *prometheus.NewSummaryVec(makePrometheusSummaryOpts(metricOpts, summaryOpts), labels),
 		metricOpts:  metricOpts,
 		summaryOpts: summaryOpts,
+		limiter:     newSeriesLimiter(metricOpts, labels),
+		checker:     newLabelValuesChecker(metricOpts, labels),
 	}
 }
 
@@ -40,3 +54,125 @@ func (c *SummaryVec) GetBaseType() MetricType {
 func (c *SummaryVec) GetCollector() prometheus.Collector {
 	return c.SummaryVec
 }
+
+// WithLabelValues works as prometheus.SummaryVec.WithLabelValues. Past the MaxSeries
+// limit, new label combinations are handled according to the OverflowPolicy,
+// and values not in AllowedLabelValues according to the LabelValuePolicy.
+func (c *SummaryVec) WithLabelValues(lvs ...string) prometheus.Observer {
+	m, err := c.GetMetricWithLabelValues(lvs...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// With works as prometheus.SummaryVec.With, with the MaxSeries limit and
+// AllowedLabelValues of WithLabelValues.
+func (c *SummaryVec) With(labels prometheus.Labels) prometheus.Observer {
+	m, err := c.GetMetricWith(labels)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// GetMetricWithLabelValues works as prometheus.SummaryVec.GetMetricWithLabelValues,
+// with the MaxSeries limit and AllowedLabelValues of WithLabelValues.
+func (c *SummaryVec) GetMetricWithLabelValues(lvs ...string) (prometheus.Observer, error) {
+	lvs, err := c.checker.check(lvs)
+	if err != nil {
+		return nil, err
+	}
+
+	lvs, ok := c.limiter.admit(lvs)
+	if !ok {
+		return c.discarded(), nil
+	}
+	return c.SummaryVec.GetMetricWithLabelValues(lvs...)
+}
+
+// GetMetricWith works as prometheus.SummaryVec.GetMetricWith, with the MaxSeries
+// limit and AllowedLabelValues of WithLabelValues.
+func (c *SummaryVec) GetMetricWith(labels prometheus.Labels) (prometheus.Observer, error) {
+	labels, err := c.checker.checkLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, ok := c.limiter.admitLabels(labels)
+	if !ok {
+		return c.discarded(), nil
+	}
+	return c.SummaryVec.GetMetricWith(labels)
+}
+
+// DeleteLabelValues works as prometheus.SummaryVec.DeleteLabelValues, and frees
+// the series from the MaxSeries limit.
+func (c *SummaryVec) DeleteLabelValues(lvs ...string) bool {
+	c.limiter.forget(lvs)
+	return c.SummaryVec.DeleteLabelValues(lvs...)
+}
+
+// Delete works as prometheus.SummaryVec.Delete, and frees the series from the
+// MaxSeries limit.
+func (c *SummaryVec) Delete(labels prometheus.Labels) bool {
+	c.limiter.forgetLabels(labels)
+	return c.SummaryVec.Delete(labels)
+}
+
+// DeletePartialMatch works as prometheus.SummaryVec.DeletePartialMatch, and frees
+// the series from the MaxSeries limit.
+func (c *SummaryVec) DeletePartialMatch(labels prometheus.Labels) int {
+	c.limiter.forgetPartialMatch(labels)
+	return c.SummaryVec.DeletePartialMatch(labels)
+}
+
+// Reset works as prometheus.SummaryVec.Reset, and frees all series from the
+// MaxSeries limit.
+func (c *SummaryVec) Reset() {
+	c.limiter.reset()
+	c.SummaryVec.Reset()
+}
+
+// deleteExpiredSeries deletes the series not accessed for longer than the
+// SeriesTTL.
+func (c *SummaryVec) deleteExpiredSeries(now time.Time) int {
+	return c.limiter.expire(now, c.SummaryVec.DeleteLabelValues)
+}
+
+// discarded returns a metric that is not collected, for the values refused
+// by the MaxSeries limit.
+func (c *SummaryVec) discarded() prometheus.Observer {
+	return prometheus.NewSummary(makePrometheusSummaryOpts(c.metricOpts, c.summaryOpts))
+}
+
+// SummaryVecT is a SummaryVec whose labels are declared by the fields of the
+// label set struct L, tagged with LabelTag.
+type SummaryVecT[L any] struct {
+	*SummaryVec
+	typedLabels[L]
+}
+
+var _ labelsValidator = &SummaryVecT[struct{}]{}
+
+// NewSummaryVecT creates a new SummaryVecT. The label set is validated when the
+// SummaryVecT is registered with the Prometheus registry through
+// RegisterMetrics.
+func NewSummaryVecT[L any](metricOpts MetricOpts, summaryOpts prometheus.SummaryOpts) *SummaryVecT[L] {
+	tl := newTypedLabels[L]()
+
+	return &SummaryVecT[L]{
+		SummaryVec:  NewSummaryVec(metricOpts, summaryOpts, tl.names),
+		typedLabels: tl,
+	}
+}
+
+// With returns the Observer for the given label set.
+func (c *SummaryVecT[L]) With(labels L) prometheus.Observer {
+	return c.SummaryVec.WithLabelValues(c.values(labels)...)
+}
+
+// Delete deletes the series of the given label set.
+func (c *SummaryVecT[L]) Delete(labels L) bool {
+	return c.SummaryVec.DeleteLabelValues(c.values(labels)...)
+}
@@ -0,0 +1,91 @@
+package operatormetrics
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/prometheus/common/model"
+)
+
+// LabelTag is the struct tag declaring the label names of the label set
+// structs of typed Vec metrics, such as NewCounterVecT.
+//
+//	type ReconcileLabels struct {
+//		Controller string `label:"controller"`
+//		Action     string `label:"action"`
+//	}
+const LabelTag = "label"
+
+// typedLabels maps the fields of a label set struct to label names.
+type typedLabels[L any] struct {
+	names  []string
+	fields []int
+	err    error
+}
+
+func newTypedLabels[L any]() typedLabels[L] {
+	var tl typedLabels[L]
+
+	t := reflect.TypeOf((*L)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		tl.err = fmt.Errorf("label set %s is not a struct", t)
+		return tl
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, ok := field.Tag.Lookup(LabelTag)
+		if !ok || name == "-" {
+			continue
+		}
+
+		if !field.IsExported() || field.Type.Kind() != reflect.String {
+			tl.err = fmt.Errorf("label set %s field %s must be an exported string", t, field.Name)
+			return tl
+		}
+
+		if !model.LabelName(name).IsValid() {
+			tl.err = fmt.Errorf("label set %s field %s has invalid label name %q", t, field.Name, name)
+			return tl
+		}
+
+		for _, existing := range tl.names {
+			if existing == name {
+				tl.err = fmt.Errorf("label set %s has duplicate label name %q", t, name)
+				return tl
+			}
+		}
+
+		tl.names = append(tl.names, name)
+		tl.fields = append(tl.fields, i)
+	}
+
+	if len(tl.names) == 0 {
+		tl.err = fmt.Errorf("label set %s has no fields with a %q tag", t, LabelTag)
+	}
+
+	return tl
+}
+
+// values returns the label values of a label set, in the order of names.
+func (tl typedLabels[L]) values(labels L) []string {
+	v := reflect.ValueOf(labels)
+
+	lvs := make([]string, len(tl.fields))
+	for i, field := range tl.fields {
+		lvs[i] = v.Field(field).String()
+	}
+
+	return lvs
+}
+
+func (tl typedLabels[L]) validateLabels() error {
+	return tl.err
+}
+
+// labelsValidator is implemented by the typed Vec metrics, whose label set is
+// validated by RegisterMetrics.
+type labelsValidator interface {
+	validateLabels() error
+}
@@ -0,0 +1,91 @@
+package operatormetrics_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+type reconcileLabels struct {
+	Controller string `label:"controller"`
+	Action     string `label:"action"`
+	Ignored    string `label:"-"`
+}
+
+type invalidLabels struct {
+	Count int `label:"count"`
+}
+
+type duplicatedLabels struct {
+	Action string `label:"action"`
+	Verb   string `label:"action"`
+}
+
+var _ = Describe("TypedLabels", func() {
+	var metricsFetcher testutil.MetricsFetcher
+
+	BeforeEach(func() {
+		metricsFetcher = useTestRegistry()
+	})
+
+	It("should set values by label set", func() {
+		counterVec := operatormetrics.NewCounterVecT[reconcileLabels](operatormetrics.MetricOpts{
+			Name: "test_typed_counter_vec",
+			Help: "A test typed counter vec",
+		})
+		histogramVec := operatormetrics.NewHistogramVecT[reconcileLabels](operatormetrics.MetricOpts{
+			Name: "test_typed_histogram_vec",
+			Help: "A test typed histogram vec",
+		}, prometheus.HistogramOpts{})
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{counterVec, histogramVec})).To(Succeed())
+
+		counterVec.With(reconcileLabels{Controller: "guestbook", Action: "create", Ignored: "x"}).Add(2)
+		counterVec.With(reconcileLabels{Controller: "guestbook", Action: "delete"}).Inc()
+		histogramVec.With(reconcileLabels{Controller: "guestbook", Action: "create"}).Observe(1)
+
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics["test_typed_counter_vec"]).To(HaveLen(2))
+		Expect(metrics).To(testutil.HaveMetric("test_typed_counter_vec").
+			WithLabels("controller", "guestbook", "action", "create").WithValue(2))
+		Expect(metrics["test_typed_counter_vec"][0].Labels).ToNot(HaveKey("Ignored"))
+
+		Expect(counterVec.Delete(reconcileLabels{Controller: "guestbook", Action: "delete"})).To(BeTrue())
+		metrics, err = metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics["test_typed_counter_vec"]).To(HaveLen(1))
+	})
+
+	It("should list the label names of the label set", func() {
+		gaugeVec := operatormetrics.NewGaugeVecT[reconcileLabels](operatormetrics.MetricOpts{
+			Name: "test_typed_gauge_vec",
+			Help: "A test typed gauge vec",
+		})
+		Expect(gaugeVec.GetType()).To(Equal(operatormetrics.GaugeVecType))
+
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{gaugeVec})).To(Succeed())
+		Expect(operatormetrics.ListMetrics()).To(ContainElement(gaugeVec))
+	})
+
+	DescribeTable("should fail to register invalid label sets", func(metric operatormetrics.Metric, errMsg string) {
+		err := operatormetrics.RegisterMetrics([]operatormetrics.Metric{metric})
+		Expect(err).To(MatchError(ContainSubstring(errMsg)))
+	},
+		Entry("with non string fields", operatormetrics.NewGaugeVecT[invalidLabels](operatormetrics.MetricOpts{
+			Name: "test_invalid_typed_gauge_vec",
+			Help: "A test invalid typed gauge vec",
+		}), "field Count must be an exported string"),
+		Entry("with duplicated label names", operatormetrics.NewSummaryVecT[duplicatedLabels](operatormetrics.MetricOpts{
+			Name: "test_duplicated_typed_summary_vec",
+			Help: "A test duplicated typed summary vec",
+		}, prometheus.SummaryOpts{}), `duplicate label name "action"`),
+		Entry("with non struct label sets", operatormetrics.NewCounterVecT[string](operatormetrics.MetricOpts{
+			Name: "test_string_typed_counter_vec",
+			Help: "A test string typed counter vec",
+		}), "is not a struct"),
+	)
+})
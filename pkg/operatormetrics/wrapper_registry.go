@@ -4,19 +4,24 @@ import (
 	"cmp"
 	"fmt"
 	"slices"
+	"sync"
 )
 
 var operatorRegistry = newRegistry()
 
 type operatorRegisterer struct {
+	// mu guards the maps below, which are read by the series cleanup and the
+	// collectors concurrently with the registration of metrics.
+	mu sync.RWMutex
+
 	registeredMetrics map[string]Metric
 
 	registeredCollectors       map[string]Collector
 	registeredCollectorMetrics map[string]Metric
 }
 
-func newRegistry() operatorRegisterer {
-	return operatorRegisterer{
+func newRegistry() *operatorRegisterer {
+	return &operatorRegisterer{
 		registeredMetrics:          map[string]Metric{},
 		registeredCollectors:       map[string]Collector{},
 		registeredCollectorMetrics: map[string]Metric{},
@@ -38,6 +43,13 @@ func RegisterMetrics(allMetrics ...[]Metric) error {
 			if err != nil {
 				return err
 			}
+
+			if metric.GetOpts().MaxSeries > 0 && !metricExists(SeriesOverflowTotal) {
+				err = registerMetric(SeriesOverflowTotal)
+				if err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -80,15 +92,13 @@ func UnregisterMetrics(allMetrics ...[]Metric) error {
 
 // ListMetrics returns a list of all registered metrics.
 func ListMetrics() []Metric {
-	var result []Metric
-
-	for _, rm := range operatorRegistry.registeredMetrics {
-		result = append(result, rm)
-	}
+	result := operatorRegistry.metrics()
 
+	operatorRegistry.mu.RLock()
 	for _, rc := range operatorRegistry.registeredCollectorMetrics {
 		result = append(result, rc)
 	}
+	operatorRegistry.mu.RUnlock()
 
 	slices.SortFunc(result, func(a, b Metric) int {
 		return cmp.Compare(a.GetOpts().Name, b.GetOpts().Name)
@@ -99,14 +109,14 @@ func ListMetrics() []Metric {
 
 // CleanRegistry removes all registered metrics.
 func CleanRegistry() error {
-	for _, metric := range operatorRegistry.registeredMetrics {
+	for _, metric := range operatorRegistry.metrics() {
 		err := unregisterMetric(metric)
 		if err != nil {
 			return err
 		}
 	}
 
-	for _, collector := range operatorRegistry.registeredCollectors {
+	for _, collector := range operatorRegistry.collectors() {
 		err := unregisterCollector(collector)
 		if err != nil {
 			return err
@@ -116,14 +126,56 @@ func CleanRegistry() error {
 	return nil
 }
 
+// metrics returns a copy of the registered metrics, so they can be iterated
+// without holding the lock.
+func (r *operatorRegisterer) metrics() []Metric {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Metric, 0, len(r.registeredMetrics))
+	for _, metric := range r.registeredMetrics {
+		result = append(result, metric)
+	}
+
+	return result
+}
+
+// collectors returns a copy of the registered collectors, so they can be
+// iterated without holding the lock.
+func (r *operatorRegisterer) collectors() []Collector {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Collector, 0, len(r.registeredCollectors))
+	for _, collector := range r.registeredCollectors {
+		result = append(result, collector)
+	}
+
+	return result
+}
+
+// collectorMetric returns the registered collector metric with the given name.
+func (r *operatorRegisterer) collectorMetric(name string) (Metric, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	metric, ok := r.registeredCollectorMetrics[name]
+	return metric, ok
+}
+
 func metricExists(metric Metric) bool {
+	operatorRegistry.mu.RLock()
+	defer operatorRegistry.mu.RUnlock()
+
 	_, ok := operatorRegistry.registeredMetrics[metric.GetOpts().Name]
 	return ok
 }
 
 func unregisterMetric(metric Metric) error {
 	if succeeded := Unregister(metric.GetCollector()); succeeded {
+		operatorRegistry.mu.Lock()
 		delete(operatorRegistry.registeredMetrics, metric.GetOpts().Name)
+		operatorRegistry.mu.Unlock()
 		return nil
 	}
 
@@ -131,22 +183,37 @@ func unregisterMetric(metric Metric) error {
 }
 
 func registerMetric(metric Metric) error {
+	if v, ok := metric.(labelsValidator); ok {
+		if err := v.validateLabels(); err != nil {
+			return fmt.Errorf("invalid metric %s: %w", metric.GetOpts().Name, err)
+		}
+	}
+
 	err := Register(metric.GetCollector())
 	if err != nil {
 		return err
 	}
+
+	operatorRegistry.mu.Lock()
 	operatorRegistry.registeredMetrics[metric.GetOpts().Name] = metric
+	operatorRegistry.mu.Unlock()
 
 	return nil
 }
 
 func collectorExists(collector Collector) bool {
+	operatorRegistry.mu.RLock()
+	defer operatorRegistry.mu.RUnlock()
+
 	_, ok := operatorRegistry.registeredCollectors[collector.hash()]
 	return ok
 }
 
 func unregisterCollector(collector Collector) error {
 	if succeeded := Unregister(collector); succeeded {
+		operatorRegistry.mu.Lock()
+		defer operatorRegistry.mu.Unlock()
+
 		delete(operatorRegistry.registeredCollectors, collector.hash())
 		for _, metric := range collector.Metrics {
 			delete(operatorRegistry.registeredCollectorMetrics, metric.GetOpts().Name)
@@ -163,6 +230,9 @@ func registerCollector(collector Collector) error {
 		return err
 	}
 
+	operatorRegistry.mu.Lock()
+	defer operatorRegistry.mu.Unlock()
+
 	operatorRegistry.registeredCollectors[collector.hash()] = collector
 	for _, cm := range collector.Metrics {
 		operatorRegistry.registeredCollectorMetrics[cm.GetOpts().Name] = cm
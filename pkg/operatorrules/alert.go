@@ -0,0 +1,166 @@
+package operatorrules
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+)
+
+// Severity is the value of the severity label of an alert.
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityWarning  Severity = "warning"
+	SeverityInfo     Severity = "info"
+)
+
+// HealthImpact is the value of the operator_health_impact label of an alert,
+// the impact of the alert on the health of the operator.
+type HealthImpact string
+
+const (
+	HealthImpactCritical HealthImpact = "critical"
+	HealthImpactWarning  HealthImpact = "warning"
+	HealthImpactNone     HealthImpact = "none"
+)
+
+const (
+	severityLabel     = "severity"
+	healthImpactLabel = "operator_health_impact"
+
+	summaryAnnotation     = "summary"
+	descriptionAnnotation = "description"
+	runbookURLAnnotation  = "runbook_url"
+)
+
+var alertNameRegex = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+
+// Alert builds a Prometheus alerting rule with the well-known labels and
+// annotations of operator alerts.
+type Alert struct {
+	// Name of the alert, in PascalCase.
+	Name string
+	Expr string
+
+	// For is how long the expression must hold before the alert fires.
+	For time.Duration
+	// KeepFiringFor is how long the alert keeps firing after the expression
+	// stops holding.
+	KeepFiringFor time.Duration
+
+	Severity     Severity
+	HealthImpact HealthImpact
+
+	Summary     string
+	Description string
+	// RunbookURL of the alert. Defaults to the runbook base URL of the
+	// registry the alert is registered with, followed by the alert name.
+	RunbookURL string
+
+	// Labels and Annotations are added to the alert. They do not override
+	// the well-known labels and annotations set by the other fields.
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// Build returns the Prometheus alerting rule, or an error if the alert is
+// not valid.
+func (a Alert) Build() (promv1.Rule, error) {
+	if err := a.validate(); err != nil {
+		return promv1.Rule{}, fmt.Errorf("invalid alert %s: %w", a.Name, err)
+	}
+
+	rule := promv1.Rule{
+		Alert:       a.Name,
+		Expr:        intstr.FromString(a.Expr),
+		Labels:      map[string]string{},
+		Annotations: map[string]string{},
+	}
+
+	if a.For > 0 {
+		rule.For = ptr.To(promv1.Duration(model.Duration(a.For).String()))
+	}
+	if a.KeepFiringFor > 0 {
+		rule.KeepFiringFor = ptr.To(promv1.NonEmptyDuration(model.Duration(a.KeepFiringFor).String()))
+	}
+
+	for k, v := range a.Labels {
+		rule.Labels[k] = v
+	}
+	rule.Labels[severityLabel] = string(a.Severity)
+	if a.HealthImpact != "" {
+		rule.Labels[healthImpactLabel] = string(a.HealthImpact)
+	}
+
+	for k, v := range a.Annotations {
+		rule.Annotations[k] = v
+	}
+	rule.Annotations[summaryAnnotation] = a.Summary
+	rule.Annotations[descriptionAnnotation] = a.Description
+	if a.RunbookURL != "" {
+		rule.Annotations[runbookURLAnnotation] = a.RunbookURL
+	}
+
+	return rule, nil
+}
+
+// MustBuild is like Build but panics if the alert is not valid.
+func (a Alert) MustBuild() promv1.Rule {
+	rule, err := a.Build()
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// BuildAlerts returns the Prometheus alerting rules of the given alerts.
+func BuildAlerts(alerts ...Alert) ([]promv1.Rule, error) {
+	var rules []promv1.Rule
+	for _, a := range alerts {
+		rule, err := a.Build()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (a Alert) validate() error {
+	if !alertNameRegex.MatchString(a.Name) {
+		return fmt.Errorf("name must be in PascalCase format")
+	}
+
+	if a.Expr == "" {
+		return fmt.Errorf("expression must be set")
+	}
+
+	switch a.Severity {
+	case SeverityCritical, SeverityWarning, SeverityInfo:
+	default:
+		return fmt.Errorf("severity must be critical, warning or info, got %q", a.Severity)
+	}
+
+	switch a.HealthImpact {
+	case "", HealthImpactCritical, HealthImpactWarning, HealthImpactNone:
+	default:
+		return fmt.Errorf("health impact must be critical, warning or none, got %q", a.HealthImpact)
+	}
+
+	if a.Summary == "" || a.Description == "" {
+		return fmt.Errorf("summary and description must be set")
+	}
+
+	if a.For < 0 || a.KeepFiringFor < 0 {
+		return fmt.Errorf("for and keep firing for durations must not be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package operatorrules_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/utils/ptr"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("Alert", func() {
+	var alert operatorrules.Alert
+
+	BeforeEach(func() {
+		alert = operatorrules.Alert{
+			Name:          "GuestbookOperatorNotReady",
+			Expr:          "guestbook_operator_number_of_ready_pods < guestbook_operator_number_of_pods",
+			For:           5 * time.Minute,
+			KeepFiringFor: 90 * time.Second,
+			Severity:      operatorrules.SeverityCritical,
+			HealthImpact:  operatorrules.HealthImpactWarning,
+			Summary:       "Guestbook operator is not ready",
+			Description:   "Guestbook operator is not ready for more than 5 minutes.",
+			Labels:        map[string]string{"team": "guestbook", "severity": "info"},
+			Annotations:   map[string]string{"dashboard": "guestbook"},
+		}
+	})
+
+	It("should build a valid alerting rule", func() {
+		rule, err := alert.Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(rule.Alert).To(Equal("GuestbookOperatorNotReady"))
+		Expect(rule.Expr.String()).To(Equal(alert.Expr))
+		Expect(rule.For).To(Equal(ptr.To(promv1.Duration("5m"))))
+		Expect(rule.KeepFiringFor).To(Equal(ptr.To(promv1.NonEmptyDuration("1m30s"))))
+		Expect(rule.Labels).To(Equal(map[string]string{
+			"team":                   "guestbook",
+			"severity":               "critical",
+			"operator_health_impact": "warning",
+		}))
+		Expect(rule.Annotations).To(Equal(map[string]string{
+			"dashboard":   "guestbook",
+			"summary":     "Guestbook operator is not ready",
+			"description": "Guestbook operator is not ready for more than 5 minutes.",
+		}))
+
+		linter := testutil.New()
+		linter.AddCustomAlertValidations(testutil.ValidateAlertHealthImpactLabel)
+		Expect(linter.LintAlert(&rule)).To(BeEmpty())
+	})
+
+	It("should set the runbook URL annotation", func() {
+		alert.RunbookURL = "https://example.com/custom"
+		rule := alert.MustBuild()
+		Expect(rule.Annotations).To(HaveKeyWithValue("runbook_url", "https://example.com/custom"))
+	})
+
+	It("should build a list of alerts", func() {
+		rules, err := operatorrules.BuildAlerts(alert, alert)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rules).To(HaveLen(2))
+
+		alert.Severity = ""
+		_, err = operatorrules.BuildAlerts(alert)
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("should fail to build invalid alerts", func(mutate func(*operatorrules.Alert), errMsg string) {
+		mutate(&alert)
+		_, err := alert.Build()
+		Expect(err).To(MatchError(ContainSubstring(errMsg)))
+		Expect(func() { alert.MustBuild() }).To(Panic())
+	},
+		Entry("with non PascalCase name", func(a *operatorrules.Alert) { a.Name = "guestbook_operator_not_ready" }, "PascalCase"),
+		Entry("without expression", func(a *operatorrules.Alert) { a.Expr = "" }, "expression must be set"),
+		Entry("with invalid severity", func(a *operatorrules.Alert) { a.Severity = "Critical" }, `got "Critical"`),
+		Entry("with invalid health impact", func(a *operatorrules.Alert) { a.HealthImpact = "high" }, `got "high"`),
+		Entry("without description", func(a *operatorrules.Alert) { a.Description = "" }, "summary and description must be set"),
+		Entry("with negative duration", func(a *operatorrules.Alert) { a.For = -time.Minute }, "must not be negative"),
+	)
+})
@@ -0,0 +1,271 @@
+package operatorrules
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	promv1alpha1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1alpha1"
+)
+
+// DefaultInhibitEqual are the labels that must have the same value in the
+// source and target alerts of the generated inhibit rules.
+var DefaultInhibitEqual = []string{"namespace", "kubernetes_operator_component"}
+
+// AlertmanagerOptions configures the Alertmanager routing and inhibition
+// derived from the registered alerts.
+type AlertmanagerOptions struct {
+	// Receiver of the registered alerts that do not match a more specific
+	// route.
+	Receiver string
+	// SeverityReceivers routes the registered alerts with a severity to a
+	// receiver.
+	SeverityReceivers map[Severity]string
+	// HealthImpactReceivers routes the registered alerts with an
+	// operator_health_impact to a receiver. Severity routes are matched first.
+	HealthImpactReceivers map[HealthImpact]string
+	// GroupBy are the labels to group the registered alerts by. When empty,
+	// the grouping of the parent route is used.
+	GroupBy []string
+	// InhibitEqual are the labels that must have the same value in the source
+	// and target alerts of the inhibit rules. Defaults to DefaultInhibitEqual.
+	InhibitEqual []string
+}
+
+// AlertmanagerRoute is a plain Alertmanager route, to be added under the root
+// route of an alertmanager.yml configuration.
+type AlertmanagerRoute struct {
+	Receiver string              `json:"receiver,omitempty"`
+	GroupBy  []string            `json:"group_by,omitempty"`
+	Matchers []string            `json:"matchers,omitempty"`
+	Routes   []AlertmanagerRoute `json:"routes,omitempty"`
+}
+
+// AlertmanagerInhibitRule is a plain Alertmanager inhibit rule.
+type AlertmanagerInhibitRule struct {
+	SourceMatchers []string `json:"source_matchers,omitempty"`
+	TargetMatchers []string `json:"target_matchers,omitempty"`
+	Equal          []string `json:"equal,omitempty"`
+}
+
+// AlertmanagerSnippet holds the plain Alertmanager route and inhibit rules of
+// the registered alerts. The receivers must be defined in the alertmanager.yml
+// configuration the snippet is added to.
+type AlertmanagerSnippet struct {
+	Route        AlertmanagerRoute         `json:"route"`
+	InhibitRules []AlertmanagerInhibitRule `json:"inhibit_rules,omitempty"`
+}
+
+// severityRank orders the severities, an alert inhibits the alerts of lower
+// ranked severities.
+var severityRank = []Severity{SeverityCritical, SeverityWarning, SeverityInfo}
+
+var healthImpactRank = []HealthImpact{HealthImpactCritical, HealthImpactWarning, HealthImpactNone}
+
+type alertmanagerRouting struct {
+	receiver     string
+	groupBy      []string
+	matchers     []promv1alpha1.Matcher
+	routes       []alertmanagerRoute
+	inhibitRules []promv1alpha1.InhibitRule
+}
+
+type alertmanagerRoute struct {
+	receiver string
+	matchers []promv1alpha1.Matcher
+}
+
+// BuildAlertmanagerConfig builds an AlertmanagerConfig object routing the
+// registered alerts to the configured receivers, with inhibit rules where
+// higher severity alerts inhibit lower severity alerts with the same
+// InhibitEqual labels. The receivers are listed without notification
+// configurations, to be completed by the user. As enforced by the
+// prometheus-operator, it only applies to alerts with a namespace label equal
+// to the given namespace.
+func (r *Registry) BuildAlertmanagerConfig(name, namespace string, labels map[string]string, opts AlertmanagerOptions) (*promv1alpha1.AlertmanagerConfig, error) {
+	routing, err := r.buildAlertmanagerRouting(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var childRoutes []apiextensionsv1.JSON
+	receivers := []string{routing.receiver}
+	for _, route := range routing.routes {
+		raw, err := json.Marshal(promv1alpha1.Route{
+			Receiver: route.receiver,
+			Matchers: route.matchers,
+		})
+		if err != nil {
+			return nil, err
+		}
+		childRoutes = append(childRoutes, apiextensionsv1.JSON{Raw: raw})
+		receivers = append(receivers, route.receiver)
+	}
+
+	slices.Sort(receivers)
+	receivers = slices.Compact(receivers)
+
+	spec := promv1alpha1.AlertmanagerConfigSpec{
+		Route: &promv1alpha1.Route{
+			Receiver: routing.receiver,
+			GroupBy:  routing.groupBy,
+			Matchers: routing.matchers,
+			Routes:   childRoutes,
+		},
+		InhibitRules: routing.inhibitRules,
+	}
+	for _, receiver := range receivers {
+		spec.Receivers = append(spec.Receivers, promv1alpha1.Receiver{Name: receiver})
+	}
+
+	return &promv1alpha1.AlertmanagerConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: promv1alpha1.SchemeGroupVersion.String(),
+			Kind:       promv1alpha1.AlertmanagerConfigKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: spec,
+	}, nil
+}
+
+// BuildAlertmanagerSnippet returns the plain Alertmanager route and inhibit
+// rules of the registered alerts, in YAML format, for consumers not running
+// the prometheus-operator.
+func (r *Registry) BuildAlertmanagerSnippet(opts AlertmanagerOptions) ([]byte, error) {
+	routing, err := r.buildAlertmanagerRouting(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	snippet := AlertmanagerSnippet{
+		Route: AlertmanagerRoute{
+			Receiver: routing.receiver,
+			GroupBy:  routing.groupBy,
+			Matchers: matcherStrings(routing.matchers),
+		},
+	}
+
+	for _, route := range routing.routes {
+		snippet.Route.Routes = append(snippet.Route.Routes, AlertmanagerRoute{
+			Receiver: route.receiver,
+			Matchers: matcherStrings(route.matchers),
+		})
+	}
+
+	for _, rule := range routing.inhibitRules {
+		snippet.InhibitRules = append(snippet.InhibitRules, AlertmanagerInhibitRule{
+			SourceMatchers: matcherStrings(rule.SourceMatch),
+			TargetMatchers: matcherStrings(rule.TargetMatch),
+			Equal:          rule.Equal,
+		})
+	}
+
+	return yaml.Marshal(snippet)
+}
+
+func (r *Registry) buildAlertmanagerRouting(opts AlertmanagerOptions) (*alertmanagerRouting, error) {
+	if opts.Receiver == "" {
+		return nil, errors.New("alertmanager receiver is required")
+	}
+
+	alerts := r.ListAlerts()
+	if len(alerts) == 0 {
+		return nil, errors.New("no alerts registered")
+	}
+
+	names := make([]string, len(alerts))
+	severities := map[Severity]bool{}
+	healthImpacts := map[HealthImpact]bool{}
+	for i, alert := range alerts {
+		names[i] = regexp.QuoteMeta(alert.Alert)
+		severities[Severity(alert.Labels[severityLabel])] = true
+		healthImpacts[HealthImpact(alert.Labels[healthImpactLabel])] = true
+	}
+
+	alertsMatcher := promv1alpha1.Matcher{
+		Name:      "alertname",
+		Value:     strings.Join(names, "|"),
+		MatchType: promv1alpha1.MatchRegexp,
+	}
+
+	routing := &alertmanagerRouting{
+		receiver: opts.Receiver,
+		groupBy:  opts.GroupBy,
+		matchers: []promv1alpha1.Matcher{alertsMatcher},
+	}
+
+	for _, severity := range severityRank {
+		if receiver := opts.SeverityReceivers[severity]; receiver != "" && severities[severity] {
+			routing.routes = append(routing.routes, alertmanagerRoute{
+				receiver: receiver,
+				matchers: []promv1alpha1.Matcher{equalMatcher(severityLabel, string(severity))},
+			})
+		}
+	}
+
+	for _, healthImpact := range healthImpactRank {
+		if receiver := opts.HealthImpactReceivers[healthImpact]; receiver != "" && healthImpacts[healthImpact] {
+			routing.routes = append(routing.routes, alertmanagerRoute{
+				receiver: receiver,
+				matchers: []promv1alpha1.Matcher{equalMatcher(healthImpactLabel, string(healthImpact))},
+			})
+		}
+	}
+
+	equal := opts.InhibitEqual
+	if len(equal) == 0 {
+		equal = DefaultInhibitEqual
+	}
+
+	for i, source := range severityRank {
+		if !severities[source] {
+			continue
+		}
+
+		var targets []string
+		for _, target := range severityRank[i+1:] {
+			if severities[target] {
+				targets = append(targets, string(target))
+			}
+		}
+		if len(targets) == 0 {
+			continue
+		}
+
+		targetMatcher := equalMatcher(severityLabel, targets[0])
+		if len(targets) > 1 {
+			targetMatcher.Value = strings.Join(targets, "|")
+			targetMatcher.MatchType = promv1alpha1.MatchRegexp
+		}
+
+		routing.inhibitRules = append(routing.inhibitRules, promv1alpha1.InhibitRule{
+			SourceMatch: []promv1alpha1.Matcher{alertsMatcher, equalMatcher(severityLabel, string(source))},
+			TargetMatch: []promv1alpha1.Matcher{alertsMatcher, targetMatcher},
+			Equal:       equal,
+		})
+	}
+
+	return routing, nil
+}
+
+func equalMatcher(name, value string) promv1alpha1.Matcher {
+	return promv1alpha1.Matcher{Name: name, Value: value, MatchType: promv1alpha1.MatchEqual}
+}
+
+func matcherStrings(matchers []promv1alpha1.Matcher) []string {
+	result := make([]string, len(matchers))
+	for i, matcher := range matchers {
+		result[i] = matcher.String()
+	}
+	return result
+}
@@ -0,0 +1,178 @@
+package operatorrules_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	promv1alpha1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1alpha1"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+)
+
+const alertmanagerSnippet = `inhibit_rules:
+- equal:
+  - namespace
+  - kubernetes_operator_component
+  source_matchers:
+  - alertname=~"OperatorDown|OperatorNotReady|OperatorReconcileErrors"
+  - severity="critical"
+  target_matchers:
+  - alertname=~"OperatorDown|OperatorNotReady|OperatorReconcileErrors"
+  - severity=~"warning|info"
+- equal:
+  - namespace
+  - kubernetes_operator_component
+  source_matchers:
+  - alertname=~"OperatorDown|OperatorNotReady|OperatorReconcileErrors"
+  - severity="warning"
+  target_matchers:
+  - alertname=~"OperatorDown|OperatorNotReady|OperatorReconcileErrors"
+  - severity="info"
+route:
+  group_by:
+  - namespace
+  - alertname
+  matchers:
+  - alertname=~"OperatorDown|OperatorNotReady|OperatorReconcileErrors"
+  receiver: default
+  routes:
+  - matchers:
+    - severity="critical"
+    receiver: pager
+  - matchers:
+    - operator_health_impact="warning"
+    receiver: ticket
+`
+
+var _ = Describe("Alertmanager", func() {
+	var or *operatorrules.Registry
+
+	opts := operatorrules.AlertmanagerOptions{
+		Receiver: "default",
+		SeverityReceivers: map[operatorrules.Severity]string{
+			operatorrules.SeverityCritical: "pager",
+		},
+		HealthImpactReceivers: map[operatorrules.HealthImpact]string{
+			operatorrules.HealthImpactWarning: "ticket",
+		},
+		GroupBy: []string{"namespace", "alertname"},
+	}
+
+	newAlert := func(name string, severity operatorrules.Severity, healthImpact operatorrules.HealthImpact) promv1.Rule {
+		return operatorrules.Alert{
+			Name:         name,
+			Expr:         "up == 0",
+			Severity:     severity,
+			HealthImpact: healthImpact,
+			Summary:      name,
+			Description:  name,
+		}.MustBuild()
+	}
+
+	BeforeEach(func() {
+		or = operatorrules.NewRegistry()
+	})
+
+	Context("with registered alerts", func() {
+		BeforeEach(func() {
+			Expect(or.RegisterAlerts([]promv1.Rule{
+				newAlert("OperatorDown", operatorrules.SeverityCritical, operatorrules.HealthImpactCritical),
+				newAlert("OperatorNotReady", operatorrules.SeverityWarning, operatorrules.HealthImpactWarning),
+				newAlert("OperatorReconcileErrors", operatorrules.SeverityInfo, operatorrules.HealthImpactNone),
+			})).To(Succeed())
+		})
+
+		It("should build the plain Alertmanager snippet", func() {
+			snippet, err := or.BuildAlertmanagerSnippet(opts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(snippet)).To(Equal(alertmanagerSnippet))
+		})
+
+		It("should build the AlertmanagerConfig", func() {
+			config, err := or.BuildAlertmanagerConfig("guestbook-operator", "default", map[string]string{"app": "guestbook"}, opts)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Kind).To(Equal(promv1alpha1.AlertmanagerConfigKind))
+			Expect(config.APIVersion).To(Equal("monitoring.coreos.com/v1alpha1"))
+			Expect(config.Name).To(Equal("guestbook-operator"))
+			Expect(config.Namespace).To(Equal("default"))
+			Expect(config.Labels).To(Equal(map[string]string{"app": "guestbook"}))
+
+			Expect(config.Spec.Receivers).To(Equal([]promv1alpha1.Receiver{
+				{Name: "default"}, {Name: "pager"}, {Name: "ticket"},
+			}))
+
+			route := config.Spec.Route
+			Expect(route.Receiver).To(Equal("default"))
+			Expect(route.GroupBy).To(Equal([]string{"namespace", "alertname"}))
+			Expect(route.Matchers).To(ConsistOf(promv1alpha1.Matcher{
+				Name:      "alertname",
+				Value:     "OperatorDown|OperatorNotReady|OperatorReconcileErrors",
+				MatchType: promv1alpha1.MatchRegexp,
+			}))
+
+			childRoutes, err := route.ChildRoutes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(childRoutes).To(Equal([]promv1alpha1.Route{
+				{
+					Receiver: "pager",
+					Matchers: []promv1alpha1.Matcher{{Name: "severity", Value: "critical", MatchType: promv1alpha1.MatchEqual}},
+				},
+				{
+					Receiver: "ticket",
+					Matchers: []promv1alpha1.Matcher{{Name: "operator_health_impact", Value: "warning", MatchType: promv1alpha1.MatchEqual}},
+				},
+			}))
+
+			Expect(config.Spec.InhibitRules).To(HaveLen(2))
+			Expect(config.Spec.InhibitRules[0].SourceMatch).To(ContainElement(
+				promv1alpha1.Matcher{Name: "severity", Value: "critical", MatchType: promv1alpha1.MatchEqual}))
+			Expect(config.Spec.InhibitRules[0].TargetMatch).To(ContainElement(
+				promv1alpha1.Matcher{Name: "severity", Value: "warning|info", MatchType: promv1alpha1.MatchRegexp}))
+			Expect(config.Spec.InhibitRules[0].Equal).To(Equal(operatorrules.DefaultInhibitEqual))
+		})
+
+		It("should use the configured inhibit equal labels", func() {
+			config, err := or.BuildAlertmanagerConfig("guestbook-operator", "default", nil, operatorrules.AlertmanagerOptions{
+				Receiver:     "default",
+				InhibitEqual: []string{"namespace"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.Spec.Receivers).To(Equal([]promv1alpha1.Receiver{{Name: "default"}}))
+			Expect(config.Spec.Route.Routes).To(BeEmpty())
+			for _, rule := range config.Spec.InhibitRules {
+				Expect(rule.Equal).To(Equal([]string{"namespace"}))
+			}
+		})
+	})
+
+	It("should not add inhibit rules without lower severity alerts", func() {
+		Expect(or.RegisterAlerts([]promv1.Rule{
+			newAlert("OperatorDown", operatorrules.SeverityCritical, operatorrules.HealthImpactCritical),
+		})).To(Succeed())
+
+		config, err := or.BuildAlertmanagerConfig("guestbook-operator", "default", nil, opts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.Spec.InhibitRules).To(BeEmpty())
+
+		childRoutes, err := config.Spec.Route.ChildRoutes()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(childRoutes).To(HaveLen(1))
+		Expect(childRoutes[0].Receiver).To(Equal("pager"))
+	})
+
+	It("should return an error without alerts", func() {
+		_, err := or.BuildAlertmanagerSnippet(opts)
+		Expect(err).To(MatchError("no alerts registered"))
+	})
+
+	It("should return an error without a receiver", func() {
+		Expect(or.RegisterAlerts([]promv1.Rule{
+			newAlert("OperatorDown", operatorrules.SeverityCritical, operatorrules.HealthImpactCritical),
+		})).To(Succeed())
+
+		_, err := or.BuildAlertmanagerConfig("guestbook-operator", "default", nil, operatorrules.AlertmanagerOptions{})
+		Expect(err).To(MatchError("alertmanager receiver is required"))
+	})
+})
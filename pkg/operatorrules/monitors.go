@@ -0,0 +1,199 @@
+package operatorrules
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+)
+
+const (
+	defaultMetricsPort   = "https"
+	defaultMetricsPath   = "/metrics"
+	defaultMetricsScheme = "https"
+)
+
+// DefaultDroppedMetrics are the regular expressions matching the high
+// cardinality series dropped by the ServiceMonitor and PodMonitor builders
+// when MonitorOptions.DroppedMetrics is nil.
+var DefaultDroppedMetrics = []string{
+	"rest_client_request_duration_seconds_bucket",
+	"rest_client_rate_limiter_duration_seconds_bucket",
+	"rest_client_request_size_bytes_bucket",
+	"rest_client_response_size_bytes_bucket",
+	"workqueue_(queue|work)_duration_seconds_bucket",
+	"controller_runtime_webhook_latency_seconds_bucket",
+}
+
+// MonitorOptions holds the scrape configuration used by BuildServiceMonitor
+// and BuildPodMonitor. The zero value scrapes the "https" port on /metrics
+// over HTTPS without authentication, verifying the serving certificate
+// against the system CAs of Prometheus. The CA and server name of the
+// serving certificate are specific to each deployment, so they are not
+// defaulted and must be given in TLSConfig.
+type MonitorOptions struct {
+	// Selector selects the Services or Pods exposing the metrics.
+	Selector metav1.LabelSelector
+
+	// Namespaces restricts the namespaces where the Services or Pods are
+	// selected. Defaults to the namespace of the monitor.
+	Namespaces []string
+
+	// Port is the name of the port exposing the metrics. Defaults to "https".
+	Port string
+
+	// Path is the HTTP path of the metrics endpoint. Defaults to "/metrics".
+	Path string
+
+	// Scheme is the HTTP scheme of the metrics endpoint. Defaults to "https".
+	Scheme string
+
+	// Interval at which the metrics are scraped. Defaults to the Prometheus
+	// global scrape interval.
+	Interval promv1.Duration
+
+	// TLSConfig is the TLS configuration used for HTTPS endpoints, such as
+	// the CA and server name of the serving certificate. When nil, no TLS
+	// configuration is set and Prometheus verifies the serving certificate
+	// against its system CAs.
+	TLSConfig *promv1.SafeTLSConfig
+
+	// InsecureSkipVerify disables the verification of the serving certificate
+	// of HTTPS endpoints. It must only be used when the endpoint does not
+	// have a certificate signed by a trusted CA.
+	InsecureSkipVerify bool
+
+	// BearerTokenSecret is the secret holding the token used to authenticate
+	// the scrapes. When nil, the scrapes are not authenticated.
+	BearerTokenSecret *corev1.SecretKeySelector
+
+	// DroppedMetrics are regular expressions matching the names of the series
+	// dropped before ingestion. Defaults to DefaultDroppedMetrics, set to an
+	// empty slice to keep all series.
+	DroppedMetrics []string
+
+	// MetricRelabelings are appended to the generated metric relabelings.
+	MetricRelabelings []*promv1.RelabelConfig
+}
+
+// BuildServiceMonitor builds a ServiceMonitor object scraping the Services
+// selected by opts.
+func BuildServiceMonitor(name, namespace string, labels map[string]string, opts MonitorOptions) *promv1.ServiceMonitor {
+	endpoint := promv1.Endpoint{
+		Port:                 valueOrDefault(opts.Port, defaultMetricsPort),
+		Path:                 valueOrDefault(opts.Path, defaultMetricsPath),
+		Scheme:               valueOrDefault(opts.Scheme, defaultMetricsScheme),
+		Interval:             opts.Interval,
+		Authorization:        buildAuthorization(opts),
+		MetricRelabelConfigs: buildMetricRelabelings(opts),
+	}
+
+	if tlsConfig := buildSafeTLSConfig(opts); tlsConfig != nil && endpoint.Scheme == "https" {
+		endpoint.TLSConfig = &promv1.TLSConfig{SafeTLSConfig: *tlsConfig}
+	}
+
+	return &promv1.ServiceMonitor{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: promv1.SchemeGroupVersion.String(),
+			Kind:       promv1.ServiceMonitorsKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: promv1.ServiceMonitorSpec{
+			Selector:          opts.Selector,
+			NamespaceSelector: promv1.NamespaceSelector{MatchNames: opts.Namespaces},
+			Endpoints:         []promv1.Endpoint{endpoint},
+		},
+	}
+}
+
+// BuildPodMonitor builds a PodMonitor object scraping the Pods selected by
+// opts.
+func BuildPodMonitor(name, namespace string, labels map[string]string, opts MonitorOptions) *promv1.PodMonitor {
+	endpoint := promv1.PodMetricsEndpoint{
+		Port:                 valueOrDefault(opts.Port, defaultMetricsPort),
+		Path:                 valueOrDefault(opts.Path, defaultMetricsPath),
+		Scheme:               valueOrDefault(opts.Scheme, defaultMetricsScheme),
+		Interval:             opts.Interval,
+		Authorization:        buildAuthorization(opts),
+		MetricRelabelConfigs: buildMetricRelabelings(opts),
+	}
+
+	if tlsConfig := buildSafeTLSConfig(opts); tlsConfig != nil && endpoint.Scheme == "https" {
+		endpoint.TLSConfig = &promv1.PodMetricsEndpointTLSConfig{SafeTLSConfig: *tlsConfig}
+	}
+
+	return &promv1.PodMonitor{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: promv1.SchemeGroupVersion.String(),
+			Kind:       promv1.PodMonitorsKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: promv1.PodMonitorSpec{
+			Selector:            opts.Selector,
+			NamespaceSelector:   promv1.NamespaceSelector{MatchNames: opts.Namespaces},
+			PodMetricsEndpoints: []promv1.PodMetricsEndpoint{endpoint},
+		},
+	}
+}
+
+func buildAuthorization(opts MonitorOptions) *promv1.SafeAuthorization {
+	if opts.BearerTokenSecret == nil {
+		return nil
+	}
+
+	return &promv1.SafeAuthorization{Credentials: opts.BearerTokenSecret}
+}
+
+func buildSafeTLSConfig(opts MonitorOptions) *promv1.SafeTLSConfig {
+	if opts.TLSConfig == nil && !opts.InsecureSkipVerify {
+		return nil
+	}
+
+	var tlsConfig promv1.SafeTLSConfig
+	if opts.TLSConfig != nil {
+		tlsConfig = *opts.TLSConfig
+	}
+
+	if opts.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return &tlsConfig
+}
+
+func buildMetricRelabelings(opts MonitorOptions) []*promv1.RelabelConfig {
+	var relabelings []*promv1.RelabelConfig
+
+	droppedMetrics := opts.DroppedMetrics
+	if droppedMetrics == nil {
+		droppedMetrics = DefaultDroppedMetrics
+	}
+
+	if len(droppedMetrics) != 0 {
+		relabelings = append(relabelings, &promv1.RelabelConfig{
+			SourceLabels: []promv1.LabelName{"__name__"},
+			Regex:        strings.Join(droppedMetrics, "|"),
+			Action:       "drop",
+		})
+	}
+
+	return append(relabelings, opts.MetricRelabelings...)
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,117 @@
+package operatorrules_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+)
+
+var _ = Describe("Monitors", func() {
+	var opts operatorrules.MonitorOptions
+
+	BeforeEach(func() {
+		opts = operatorrules.MonitorOptions{
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "guestbook-operator"},
+			},
+		}
+	})
+
+	Context("ServiceMonitor", func() {
+		It("should build a ServiceMonitor with HTTPS defaults", func() {
+			sm := operatorrules.BuildServiceMonitor("guestbook-operator", "default", map[string]string{"app": "guestbook"}, opts)
+
+			Expect(sm.Name).To(Equal("guestbook-operator"))
+			Expect(sm.Namespace).To(Equal("default"))
+			Expect(sm.Kind).To(Equal(promv1.ServiceMonitorsKind))
+			Expect(sm.Spec.Selector.MatchLabels).To(HaveKeyWithValue("app", "guestbook-operator"))
+
+			Expect(sm.Spec.Endpoints).To(HaveLen(1))
+			endpoint := sm.Spec.Endpoints[0]
+			Expect(endpoint.Port).To(Equal("https"))
+			Expect(endpoint.Path).To(Equal("/metrics"))
+			Expect(endpoint.Scheme).To(Equal("https"))
+			Expect(endpoint.BearerTokenFile).To(BeEmpty())
+			Expect(endpoint.Authorization).To(BeNil())
+			Expect(endpoint.TLSConfig).To(BeNil())
+
+			Expect(endpoint.MetricRelabelConfigs).To(HaveLen(1))
+			Expect(endpoint.MetricRelabelConfigs[0].Action).To(Equal("drop"))
+			Expect(endpoint.MetricRelabelConfigs[0].SourceLabels).To(ConsistOf(promv1.LabelName("__name__")))
+			Expect(endpoint.MetricRelabelConfigs[0].Regex).To(ContainSubstring("rest_client_request_duration_seconds_bucket"))
+		})
+
+		It("should use the given authorization and TLS configuration", func() {
+			opts.BearerTokenSecret = &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "metrics-token"},
+				Key:                  "token",
+			}
+			opts.TLSConfig = &promv1.SafeTLSConfig{ServerName: "guestbook-operator.default.svc"}
+
+			sm := operatorrules.BuildServiceMonitor("guestbook-operator", "default", nil, opts)
+
+			endpoint := sm.Spec.Endpoints[0]
+			Expect(endpoint.BearerTokenFile).To(BeEmpty())
+			Expect(endpoint.Authorization.Credentials.Name).To(Equal("metrics-token"))
+			Expect(endpoint.Authorization.Credentials.Key).To(Equal("token"))
+			Expect(endpoint.TLSConfig.InsecureSkipVerify).To(BeFalse())
+			Expect(endpoint.TLSConfig.ServerName).To(Equal("guestbook-operator.default.svc"))
+		})
+
+		It("should not set a TLS configuration for HTTP endpoints", func() {
+			opts.Scheme = "http"
+			opts.Port = "metrics"
+			opts.TLSConfig = &promv1.SafeTLSConfig{ServerName: "guestbook-operator.default.svc"}
+
+			sm := operatorrules.BuildServiceMonitor("guestbook-operator", "default", nil, opts)
+
+			Expect(sm.Spec.Endpoints[0].Port).To(Equal("metrics"))
+			Expect(sm.Spec.Endpoints[0].TLSConfig).To(BeNil())
+		})
+	})
+
+	Context("PodMonitor", func() {
+		It("should build a PodMonitor with HTTPS defaults", func() {
+			pm := operatorrules.BuildPodMonitor("guestbook-operator", "default", nil, opts)
+
+			Expect(pm.Kind).To(Equal(promv1.PodMonitorsKind))
+			Expect(pm.Spec.PodMetricsEndpoints).To(HaveLen(1))
+
+			endpoint := pm.Spec.PodMetricsEndpoints[0]
+			Expect(endpoint.Port).To(Equal("https"))
+			Expect(endpoint.Scheme).To(Equal("https"))
+			Expect(endpoint.TLSConfig).To(BeNil())
+			Expect(endpoint.Authorization).To(BeNil())
+		})
+
+		It("should skip the certificate verification only when requested", func() {
+			opts.InsecureSkipVerify = true
+
+			pm := operatorrules.BuildPodMonitor("guestbook-operator", "default", nil, opts)
+
+			endpoint := pm.Spec.PodMetricsEndpoints[0]
+			Expect(endpoint.TLSConfig.InsecureSkipVerify).To(BeTrue())
+			Expect(endpoint.TLSConfig.CA).To(Equal(promv1.SecretOrConfigMap{}))
+			Expect(endpoint.Authorization).To(BeNil())
+		})
+
+		It("should keep all series when dropped metrics is empty", func() {
+			opts.DroppedMetrics = []string{}
+			opts.MetricRelabelings = []*promv1.RelabelConfig{
+				{Action: "labeldrop", Regex: "instance"},
+			}
+
+			pm := operatorrules.BuildPodMonitor("guestbook-operator", "default", nil, opts)
+
+			relabelings := pm.Spec.PodMetricsEndpoints[0].MetricRelabelConfigs
+			Expect(relabelings).To(HaveLen(1))
+			Expect(relabelings[0].Action).To(Equal("labeldrop"))
+		})
+	})
+})
@@ -36,14 +36,14 @@ func (r *Registry) buildPrometheusRuleSpec() (*promv1.PrometheusRuleSpec, error)
 
 	if len(r.registeredRecordingRules) != 0 {
 		groups = append(groups, promv1.RuleGroup{
-			Name:  "recordingRules.rules",
+			Name:  recordingRulesGroupName,
 			Rules: r.buildRecordingRulesRules(),
 		})
 	}
 
 	if len(r.registeredAlerts) != 0 {
 		groups = append(groups, promv1.RuleGroup{
-			Name:  "alerts.rules",
+			Name:  alertsGroupName,
 			Rules: r.ListAlerts(),
 		})
 	}
@@ -5,41 +5,196 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// RBACOptions holds the configuration used by BuildRBAC.
+type RBACOptions struct {
+	// NamePrefix is the prefix of the name of every built object.
+	NamePrefix string
+
+	// Namespaces where Prometheus is allowed to discover the scrape targets. A
+	// Role and RoleBinding are built for each namespace.
+	Namespaces []string
+
+	// ClusterWide builds a ClusterRole and ClusterRoleBinding allowing
+	// Prometheus to discover the scrape targets in all namespaces, instead of
+	// the per-namespace Roles and RoleBindings.
+	ClusterWide bool
+
+	// PrometheusSAName is the name of the Prometheus service account.
+	PrometheusSAName string
+
+	// PrometheusSANamespace is the namespace of the Prometheus service account.
+	PrometheusSANamespace string
+
+	// Labels are set on every built object.
+	Labels map[string]string
+
+	// Watch grants the watch verb, used by Prometheus service discovery, in
+	// addition to get and list.
+	Watch bool
+
+	// EndpointSlices grants access to endpointslices.discovery.k8s.io, used
+	// by Prometheus when the EndpointSlice service discovery role is set.
+	EndpointSlices bool
+
+	// MetricsReader builds a ClusterRole and ClusterRoleBinding granting get
+	// on the /metrics non-resource URL, required when the metrics endpoint is
+	// protected by kube-rbac-proxy.
+	MetricsReader bool
+}
+
+// RBACObjects holds the objects built by BuildRBAC.
+type RBACObjects struct {
+	Roles               []*rbacv1.Role
+	RoleBindings        []*rbacv1.RoleBinding
+	ClusterRoles        []*rbacv1.ClusterRole
+	ClusterRoleBindings []*rbacv1.ClusterRoleBinding
+}
+
+// BuildRoleAndRoleBinding builds a Role and RoleBinding allowing the given
+// Prometheus service account to discover the scrape targets in namespace.
 func BuildRoleAndRoleBinding(namePrefix, namespace, promSAName, promSANamespace string, labels map[string]string) (*rbacv1.Role, *rbacv1.RoleBinding) {
-	r := &rbacv1.Role{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      namePrefix + "-role",
-			Namespace: namespace,
-			Labels:    labels,
-		},
-		Rules: []rbacv1.PolicyRule{
+	objs := BuildRBAC(RBACOptions{
+		NamePrefix:            namePrefix,
+		Namespaces:            []string{namespace},
+		PrometheusSAName:      promSAName,
+		PrometheusSANamespace: promSANamespace,
+		Labels:                labels,
+	})
+
+	return objs.Roles[0], objs.RoleBindings[0]
+}
+
+// BuildRBAC builds the RBAC objects required by Prometheus to scrape the
+// operator metrics, according to the given options.
+func BuildRBAC(opts RBACOptions) *RBACObjects {
+	objs := &RBACObjects{}
+	rules := buildDiscoveryPolicyRules(opts)
+
+	if opts.ClusterWide {
+		name := opts.NamePrefix + "-clusterrole"
+		objs.ClusterRoles = append(objs.ClusterRoles, buildClusterRole(name, rules, opts))
+		objs.ClusterRoleBindings = append(objs.ClusterRoleBindings, buildClusterRoleBinding(opts.NamePrefix+"-clusterrolebinding", name, opts))
+	} else {
+		for _, namespace := range opts.Namespaces {
+			objs.Roles = append(objs.Roles, buildRole(namespace, rules, opts))
+			objs.RoleBindings = append(objs.RoleBindings, buildRoleBinding(namespace, opts))
+		}
+	}
+
+	if opts.MetricsReader {
+		name := opts.NamePrefix + "-metrics-clusterrole"
+		metricsRules := []rbacv1.PolicyRule{
 			{
-				APIGroups: []string{""},
-				Resources: []string{"services", "endpoints", "pods"},
-				Verbs:     []string{"get", "list"},
+				NonResourceURLs: []string{"/metrics"},
+				Verbs:           []string{"get"},
 			},
+		}
+		objs.ClusterRoles = append(objs.ClusterRoles, buildClusterRole(name, metricsRules, opts))
+		objs.ClusterRoleBindings = append(objs.ClusterRoleBindings, buildClusterRoleBinding(opts.NamePrefix+"-metrics-clusterrolebinding", name, opts))
+	}
+
+	return objs
+}
+
+func buildDiscoveryPolicyRules(opts RBACOptions) []rbacv1.PolicyRule {
+	verbs := []string{"get", "list"}
+	if opts.Watch {
+		verbs = append(verbs, "watch")
+	}
+
+	rules := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"services", "endpoints", "pods"},
+			Verbs:     verbs,
+		},
+	}
+
+	if opts.EndpointSlices {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{"discovery.k8s.io"},
+			Resources: []string{"endpointslices"},
+			Verbs:     verbs,
+		})
+	}
+
+	return rules
+}
+
+func buildRole(namespace string, rules []rbacv1.PolicyRule, opts RBACOptions) *rbacv1.Role {
+	return &rbacv1.Role{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "Role",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      opts.NamePrefix + "-role",
+			Namespace: namespace,
+			Labels:    opts.Labels,
 		},
+		Rules: rules,
 	}
+}
 
-	rb := &rbacv1.RoleBinding{
+func buildRoleBinding(namespace string, opts RBACOptions) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "RoleBinding",
+		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      namePrefix + "-rolebinding",
+			Name:      opts.NamePrefix + "-rolebinding",
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    opts.Labels,
 		},
 		RoleRef: rbacv1.RoleRef{
 			Kind:     "Role",
-			Name:     namePrefix + "-role",
+			Name:     opts.NamePrefix + "-role",
 			APIGroup: rbacv1.GroupName,
 		},
-		Subjects: []rbacv1.Subject{
-			{
-				Kind:      "ServiceAccount",
-				Name:      promSAName,
-				Namespace: promSANamespace,
-			},
+		Subjects: buildPrometheusSubjects(opts),
+	}
+}
+
+func buildClusterRole(name string, rules []rbacv1.PolicyRule, opts RBACOptions) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "ClusterRole",
 		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: opts.Labels,
+		},
+		Rules: rules,
+	}
+}
+
+func buildClusterRoleBinding(name, clusterRoleName string, opts RBACOptions) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "ClusterRoleBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: opts.Labels,
+		},
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "ClusterRole",
+			Name:     clusterRoleName,
+			APIGroup: rbacv1.GroupName,
+		},
+		Subjects: buildPrometheusSubjects(opts),
 	}
+}
 
-	return r, rb
+func buildPrometheusSubjects(opts RBACOptions) []rbacv1.Subject {
+	return []rbacv1.Subject{
+		{
+			Kind:      "ServiceAccount",
+			Name:      opts.PrometheusSAName,
+			Namespace: opts.PrometheusSANamespace,
+		},
+	}
 }
@@ -0,0 +1,117 @@
+package operatorrules_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+)
+
+var _ = Describe("RBAC", func() {
+	Context("BuildRoleAndRoleBinding", func() {
+		It("should build a Role and RoleBinding for the Prometheus service account", func() {
+			role, roleBinding := operatorrules.BuildRoleAndRoleBinding("guestbook", "default", "prometheus-k8s", "monitoring", map[string]string{"app": "guestbook"})
+
+			Expect(role.Name).To(Equal("guestbook-role"))
+			Expect(role.Namespace).To(Equal("default"))
+			Expect(role.Labels).To(HaveKeyWithValue("app", "guestbook"))
+			Expect(role.Rules).To(ConsistOf(rbacv1.PolicyRule{
+				APIGroups: []string{""},
+				Resources: []string{"services", "endpoints", "pods"},
+				Verbs:     []string{"get", "list"},
+			}))
+
+			Expect(roleBinding.Name).To(Equal("guestbook-rolebinding"))
+			Expect(roleBinding.Namespace).To(Equal("default"))
+			Expect(roleBinding.RoleRef.Kind).To(Equal("Role"))
+			Expect(roleBinding.RoleRef.Name).To(Equal("guestbook-role"))
+			Expect(roleBinding.Subjects).To(ConsistOf(rbacv1.Subject{
+				Kind:      "ServiceAccount",
+				Name:      "prometheus-k8s",
+				Namespace: "monitoring",
+			}))
+		})
+	})
+
+	Context("BuildRBAC", func() {
+		var opts operatorrules.RBACOptions
+
+		BeforeEach(func() {
+			opts = operatorrules.RBACOptions{
+				NamePrefix:            "guestbook",
+				Namespaces:            []string{"default"},
+				PrometheusSAName:      "prometheus-k8s",
+				PrometheusSANamespace: "monitoring",
+			}
+		})
+
+		It("should build a Role and RoleBinding per namespace", func() {
+			opts.Namespaces = []string{"ns1", "ns2"}
+
+			objs := operatorrules.BuildRBAC(opts)
+
+			Expect(objs.Roles).To(HaveLen(2))
+			Expect(objs.RoleBindings).To(HaveLen(2))
+			Expect(objs.Roles[0].Namespace).To(Equal("ns1"))
+			Expect(objs.Roles[1].Namespace).To(Equal("ns2"))
+			Expect(objs.RoleBindings[0].Namespace).To(Equal("ns1"))
+			Expect(objs.RoleBindings[1].Namespace).To(Equal("ns2"))
+			Expect(objs.ClusterRoles).To(BeEmpty())
+			Expect(objs.ClusterRoleBindings).To(BeEmpty())
+		})
+
+		It("should grant watch and endpointslices access", func() {
+			opts.Watch = true
+			opts.EndpointSlices = true
+
+			objs := operatorrules.BuildRBAC(opts)
+
+			Expect(objs.Roles[0].Rules).To(ConsistOf(
+				rbacv1.PolicyRule{
+					APIGroups: []string{""},
+					Resources: []string{"services", "endpoints", "pods"},
+					Verbs:     []string{"get", "list", "watch"},
+				},
+				rbacv1.PolicyRule{
+					APIGroups: []string{"discovery.k8s.io"},
+					Resources: []string{"endpointslices"},
+					Verbs:     []string{"get", "list", "watch"},
+				},
+			))
+		})
+
+		It("should build a ClusterRole and ClusterRoleBinding when cluster wide", func() {
+			opts.ClusterWide = true
+
+			objs := operatorrules.BuildRBAC(opts)
+
+			Expect(objs.Roles).To(BeEmpty())
+			Expect(objs.RoleBindings).To(BeEmpty())
+			Expect(objs.ClusterRoles).To(HaveLen(1))
+			Expect(objs.ClusterRoles[0].Name).To(Equal("guestbook-clusterrole"))
+			Expect(objs.ClusterRoleBindings).To(HaveLen(1))
+			Expect(objs.ClusterRoleBindings[0].Name).To(Equal("guestbook-clusterrolebinding"))
+			Expect(objs.ClusterRoleBindings[0].RoleRef.Kind).To(Equal("ClusterRole"))
+			Expect(objs.ClusterRoleBindings[0].RoleRef.Name).To(Equal("guestbook-clusterrole"))
+		})
+
+		It("should build the /metrics reader ClusterRole", func() {
+			opts.MetricsReader = true
+
+			objs := operatorrules.BuildRBAC(opts)
+
+			Expect(objs.Roles).To(HaveLen(1))
+			Expect(objs.ClusterRoles).To(HaveLen(1))
+			Expect(objs.ClusterRoles[0].Name).To(Equal("guestbook-metrics-clusterrole"))
+			Expect(objs.ClusterRoles[0].Rules).To(ConsistOf(rbacv1.PolicyRule{
+				NonResourceURLs: []string{"/metrics"},
+				Verbs:           []string{"get"},
+			}))
+			Expect(objs.ClusterRoleBindings).To(HaveLen(1))
+			Expect(objs.ClusterRoleBindings[0].RoleRef.Name).To(Equal("guestbook-metrics-clusterrole"))
+			Expect(objs.ClusterRoleBindings[0].Subjects[0].Name).To(Equal("prometheus-k8s"))
+		})
+	})
+})
@@ -0,0 +1,156 @@
+package operatorrules
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+)
+
+// DefaultRecordingRuleWindows are the windows of the recording rules generated
+// by a RecordingRuleTemplate without Windows.
+var DefaultRecordingRuleWindows = []string{"5m", "30m", "1h", "6h"}
+
+// RecordingRuleTemplate generates a family of recording rules, one for each
+// window, named after the Prometheus level:metric:operations convention, such
+// as namespace:guestbook_operator_reconcile_count:rate5m.
+type RecordingRuleTemplate struct {
+	// MetricsOpts of the generated recording rules. The Name, composed with
+	// the Namespace and Subsystem, is the metric part of their names, and the
+	// window is appended to the Help.
+	MetricsOpts operatormetrics.MetricOpts
+	MetricType  operatormetrics.MetricType
+
+	// GroupBy are the labels the expression aggregates by.
+	GroupBy []string
+	// Level is the level part of the names. Defaults to the GroupBy labels
+	// joined by underscores, or "cluster" without GroupBy labels.
+	Level string
+	// Operation is the operations part of the names, followed by the window,
+	// such as "rate" or "increase".
+	Operation string
+	// Windows are the ranges of the generated recording rules. Defaults to
+	// DefaultRecordingRuleWindows.
+	Windows []string
+
+	// Expr is a text/template of the expression, with the {{.Window}} and
+	// {{.GroupBy}} placeholders, the latter holding the GroupBy labels joined
+	// by commas. For example:
+	//
+	//	sum by ({{.GroupBy}}) (rate(guestbook_operator_reconcile_count[{{.Window}}]))
+	Expr string
+}
+
+type recordingRuleTemplateData struct {
+	Window  string
+	GroupBy string
+}
+
+// Build returns the recording rules generated by the template.
+func (t RecordingRuleTemplate) Build() ([]RecordingRule, error) {
+	metric := prometheus.BuildFQName(t.MetricsOpts.Namespace, t.MetricsOpts.Subsystem, t.MetricsOpts.Name)
+	if metric == "" {
+		return nil, fmt.Errorf("recording rule template must have a name")
+	}
+
+	if err := t.validate(); err != nil {
+		return nil, fmt.Errorf("invalid recording rule template %s: %w", metric, err)
+	}
+
+	tpl, err := template.New(metric).Parse(t.Expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recording rule template %s expression: %w", metric, err)
+	}
+
+	windows := t.Windows
+	if len(windows) == 0 {
+		windows = DefaultRecordingRuleWindows
+	}
+
+	var recordingRules []RecordingRule
+	for _, window := range windows {
+		buf := bytes.NewBufferString("")
+		err := tpl.Execute(buf, recordingRuleTemplateData{
+			Window:  window,
+			GroupBy: strings.Join(t.GroupBy, ", "),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute recording rule template %s expression: %w", metric, err)
+		}
+
+		metricsOpts := t.MetricsOpts
+		metricsOpts.Name = fmt.Sprintf("%s:%s:%s%s", t.level(), metric, t.Operation, window)
+		metricsOpts.Namespace = ""
+		metricsOpts.Subsystem = ""
+		if metricsOpts.Help != "" {
+			metricsOpts.Help = fmt.Sprintf("%s over %s", metricsOpts.Help, window)
+		}
+
+		recordingRules = append(recordingRules, RecordingRule{
+			MetricsOpts: metricsOpts,
+			MetricType:  t.MetricType,
+			Expr:        intstr.FromString(buf.String()),
+		})
+	}
+
+	return recordingRules, nil
+}
+
+func (t RecordingRuleTemplate) validate() error {
+	if t.Operation == "" {
+		return fmt.Errorf("operation must be set")
+	}
+
+	if t.Expr == "" {
+		return fmt.Errorf("expression must be set")
+	}
+
+	for _, label := range t.GroupBy {
+		if !model.LabelName(label).IsValid() {
+			return fmt.Errorf("invalid group by label %q", label)
+		}
+	}
+
+	for _, window := range t.Windows {
+		if _, err := model.ParseDuration(window); err != nil {
+			return fmt.Errorf("invalid window %q: %w", window, err)
+		}
+	}
+
+	return nil
+}
+
+func (t RecordingRuleTemplate) level() string {
+	if t.Level != "" {
+		return t.Level
+	}
+
+	if len(t.GroupBy) == 0 {
+		return "cluster"
+	}
+
+	return strings.Join(t.GroupBy, "_")
+}
+
+// RegisterRecordingRuleTemplates registers the recording rules generated by
+// the given templates.
+func (r *Registry) RegisterRecordingRuleTemplates(templates ...RecordingRuleTemplate) error {
+	for _, t := range templates {
+		recordingRules, err := t.Build()
+		if err != nil {
+			return err
+		}
+
+		if err := r.RegisterRecordingRules(recordingRules); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package operatorrules_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+)
+
+var _ = Describe("RecordingRuleTemplate", func() {
+	It("should generate a recording rule for each window", func() {
+		recordingRules, err := operatorrules.RecordingRuleTemplate{
+			MetricsOpts: operatormetrics.MetricOpts{
+				Name:        "guestbook_operator_reconcile_count",
+				Help:        "Rate of reconciles per namespace",
+				ConstLabels: map[string]string{"controller": "guestbook"},
+			},
+			MetricType: operatormetrics.GaugeType,
+			GroupBy:    []string{"namespace"},
+			Operation:  "rate",
+			Windows:    []string{"5m", "1h"},
+			Expr:       "sum by ({{.GroupBy}}) (rate(guestbook_operator_reconcile_count[{{.Window}}]))",
+		}.Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recordingRules).To(HaveLen(2))
+
+		Expect(recordingRules[0].MetricsOpts.Name).To(Equal("namespace:guestbook_operator_reconcile_count:rate5m"))
+		Expect(recordingRules[0].MetricsOpts.Help).To(Equal("Rate of reconciles per namespace over 5m"))
+		Expect(recordingRules[0].MetricsOpts.ConstLabels).To(HaveKeyWithValue("controller", "guestbook"))
+		Expect(recordingRules[0].MetricType).To(Equal(operatormetrics.GaugeType))
+		Expect(recordingRules[0].Expr.String()).To(Equal("sum by (namespace) (rate(guestbook_operator_reconcile_count[5m]))"))
+
+		Expect(recordingRules[1].MetricsOpts.Name).To(Equal("namespace:guestbook_operator_reconcile_count:rate1h"))
+		Expect(recordingRules[1].Expr.String()).To(Equal("sum by (namespace) (rate(guestbook_operator_reconcile_count[1h]))"))
+	})
+
+	It("should default the level and windows", func() {
+		recordingRules, err := operatorrules.RecordingRuleTemplate{
+			MetricsOpts: operatormetrics.MetricOpts{Subsystem: "workqueue", Name: "adds_total"},
+			Operation:   "increase",
+			Expr:        "sum(increase(workqueue_adds_total[{{.Window}}]))",
+		}.Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		var names []string
+		for _, rr := range recordingRules {
+			names = append(names, rr.MetricsOpts.Name)
+		}
+		Expect(names).To(Equal([]string{
+			"cluster:workqueue_adds_total:increase5m",
+			"cluster:workqueue_adds_total:increase30m",
+			"cluster:workqueue_adds_total:increase1h",
+			"cluster:workqueue_adds_total:increase6h",
+		}))
+	})
+
+	It("should register the generated recording rules with the name prefix", func() {
+		registry := operatorrules.NewRegistry(operatorrules.WithNamePrefix("guestbook_operator_"))
+		err := registry.RegisterRecordingRuleTemplates(operatorrules.RecordingRuleTemplate{
+			MetricsOpts: operatormetrics.MetricOpts{Name: "reconcile_count"},
+			GroupBy:     []string{"namespace", "controller"},
+			Operation:   "rate",
+			Windows:     []string{"5m"},
+			Expr:        "sum by ({{.GroupBy}}) (rate(guestbook_operator_reconcile_count[{{.Window}}]))",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		recordingRules := registry.ListRecordingRules()
+		Expect(recordingRules).To(HaveLen(1))
+		Expect(recordingRules[0].MetricsOpts.Name).To(Equal("namespace_controller:guestbook_operator_reconcile_count:rate5m"))
+		Expect(recordingRules[0].Expr.String()).To(Equal(
+			"sum by (namespace, controller) (rate(guestbook_operator_reconcile_count[5m]))"))
+	})
+
+	DescribeTable("should fail to build invalid templates", func(t operatorrules.RecordingRuleTemplate, errMsg string) {
+		_, err := t.Build()
+		Expect(err).To(MatchError(ContainSubstring(errMsg)))
+	},
+		Entry("without name", operatorrules.RecordingRuleTemplate{Operation: "rate", Expr: "up"},
+			"must have a name"),
+		Entry("without operation", operatorrules.RecordingRuleTemplate{
+			MetricsOpts: operatormetrics.MetricOpts{Name: "up"}, Expr: "up",
+		}, "operation must be set"),
+		Entry("with invalid windows", operatorrules.RecordingRuleTemplate{
+			MetricsOpts: operatormetrics.MetricOpts{Name: "up"}, Operation: "rate", Expr: "up", Windows: []string{"5 minutes"},
+		}, `invalid window "5 minutes"`),
+		Entry("with invalid group by labels", operatorrules.RecordingRuleTemplate{
+			MetricsOpts: operatormetrics.MetricOpts{Name: "up"}, Operation: "rate", Expr: "up", GroupBy: []string{"pod-name"},
+		}, `invalid group by label "pod-name"`),
+		Entry("with invalid expression template", operatorrules.RecordingRuleTemplate{
+			MetricsOpts: operatormetrics.MetricOpts{Name: "up"}, Operation: "rate", Expr: "rate(up[{{.Range}}])",
+		}, "failed to execute"),
+	)
+})
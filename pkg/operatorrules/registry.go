@@ -2,47 +2,119 @@ package operatorrules
 
 import (
 	"cmp"
+	"maps"
 	"slices"
+	"strings"
 
 	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
 )
 
 type Registry struct {
 	registeredRecordingRules map[string]RecordingRule
 	registeredAlerts         map[string]promv1.Rule
+
+	namePrefix     string
+	commonLabels   map[string]string
+	runbookBaseURL string
+}
+
+// RegistryOption configures a Registry.
+type RegistryOption func(*Registry)
+
+// WithNamePrefix sets the prefix prepended to the names of the recording
+// rules, such as the operatormetrics.OptsBuilder name prefix of the metrics.
+func WithNamePrefix(prefix string) RegistryOption {
+	return func(r *Registry) {
+		r.namePrefix = prefix
+	}
 }
 
-func NewRegistry() *Registry {
-	return &Registry{
+// WithCommonLabels sets the labels added to the recording rules and alerts,
+// such as the operatormetrics.OptsBuilder common labels of the metrics. Labels
+// set on a rule take precedence, and an empty value drops the label from the
+// rule.
+func WithCommonLabels(labels map[string]string) RegistryOption {
+	return func(r *Registry) {
+		r.commonLabels = maps.Clone(labels)
+	}
+}
+
+// WithRunbookBaseURL sets the base URL of the runbook_url annotation of the
+// alerts registered without one, followed by the alert name, such as
+// "https://example.com/runbooks".
+func WithRunbookBaseURL(baseURL string) RegistryOption {
+	return func(r *Registry) {
+		r.runbookBaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// NewRegistry creates a new Registry.
+func NewRegistry(opts ...RegistryOption) *Registry {
+	r := &Registry{
 		registeredRecordingRules: map[string]RecordingRule{},
 		registeredAlerts:         map[string]promv1.Rule{},
 	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
-// RegisterRecordingRules registers the given recording rules.
+// RegisterRecordingRules registers the given recording rules. Their names are
+// composed of the name prefix, Namespace, Subsystem and Name, and the common
+// labels are added to their ConstLabels.
 func (r *Registry) RegisterRecordingRules(recordingRules ...[]RecordingRule) error {
 	for _, recordingRuleList := range recordingRules {
 		for _, recordingRule := range recordingRuleList {
-			key := recordingRule.MetricsOpts.Name + ":" + recordingRule.Expr.String()
-			r.registeredRecordingRules[key] = recordingRule
+			opts := recordingRule.MetricsOpts
+			opts.Name = opts.FullNameWithPrefix(r.namePrefix)
+			opts.Namespace = ""
+			opts.Subsystem = ""
+			opts.ConstLabels = operatormetrics.MergeConstLabels(r.commonLabels, opts.ConstLabels)
+			recordingRule.MetricsOpts = opts
+
+			r.registerRecordingRule(recordingRule)
 		}
 	}
 
 	return nil
 }
 
-// RegisterAlerts registers the given alerts.
+// RegisterAlerts registers the given alerts. The common labels are added to
+// their labels, and the runbook_url annotation is set from the runbook base URL
+// when they have none.
 func (r *Registry) RegisterAlerts(alerts ...[]promv1.Rule) error {
 	for _, alertList := range alerts {
 		for _, alert := range alertList {
-			key := alert.Alert + ":" + alert.Expr.String()
-			r.registeredAlerts[key] = alert
+			alert.Labels = operatormetrics.MergeConstLabels(r.commonLabels, alert.Labels)
+			if r.runbookBaseURL != "" && alert.Annotations[runbookURLAnnotation] == "" {
+				alert.Annotations = maps.Clone(alert.Annotations)
+				if alert.Annotations == nil {
+					alert.Annotations = map[string]string{}
+				}
+				alert.Annotations[runbookURLAnnotation] = r.runbookBaseURL + "/" + alert.Alert
+			}
+			r.registerAlert(alert)
 		}
 	}
 
 	return nil
 }
 
+func (r *Registry) registerRecordingRule(recordingRule RecordingRule) {
+	key := recordingRule.MetricsOpts.Name + ":" + recordingRule.Expr.String()
+	r.registeredRecordingRules[key] = recordingRule
+}
+
+func (r *Registry) registerAlert(alert promv1.Rule) {
+	key := alert.Alert + ":" + alert.Expr.String()
+	r.registeredAlerts[key] = alert
+}
+
 // ListRecordingRules returns the registered recording rules.
 func (r *Registry) ListRecordingRules() []RecordingRule {
 	var rules []RecordingRule
@@ -39,6 +39,74 @@ var _ = Describe("OperatorRules", func() {
 			Expect(registeredRules).To(ConsistOf(recordingRules))
 		})
 
+		It("should compose recording rule names with the name prefix, namespace and subsystem", func() {
+			or = operatorrules.NewRegistry(operatorrules.WithNamePrefix("guestbook_operator_"))
+			err := or.RegisterRecordingRules([]operatorrules.RecordingRule{
+				{
+					MetricsOpts: operatormetrics.MetricOpts{Name: "ready_pods", Subsystem: "deployment"},
+					Expr:        intstr.FromString("sum(kube_pod_status_ready)"),
+				},
+				{
+					MetricsOpts: operatormetrics.MetricOpts{Name: "guestbook_operator_up"},
+					Expr:        intstr.FromString("sum(up)"),
+				},
+				{
+					MetricsOpts: operatormetrics.MetricOpts{Name: "namespace:ready_pods:sum"},
+					Expr:        intstr.FromString("sum by (namespace) (kube_pod_status_ready)"),
+				},
+			})
+			Expect(err).To(BeNil())
+
+			registeredRules := or.ListRecordingRules()
+			Expect(registeredRules).To(HaveLen(3))
+			Expect(registeredRules[0].MetricsOpts.Name).To(Equal("guestbook_operator_deployment_ready_pods"))
+			Expect(registeredRules[0].MetricsOpts.Subsystem).To(BeEmpty())
+			Expect(registeredRules[1].MetricsOpts.Name).To(Equal("guestbook_operator_guestbook_operator_up"))
+			Expect(registeredRules[2].MetricsOpts.Name).To(Equal("namespace:guestbook_operator_ready_pods:sum"))
+		})
+
+		It("should add the common const labels to recording rules", func() {
+			or = operatorrules.NewRegistry(operatorrules.WithCommonLabels(map[string]string{"kubernetes_operator_part_of": "guestbook"}))
+			err := or.RegisterRecordingRules([]operatorrules.RecordingRule{
+				{
+					MetricsOpts: operatormetrics.MetricOpts{
+						Name:        "ExampleRecordingRule1",
+						ConstLabels: map[string]string{"controller": "guestbook"},
+					},
+					Expr: intstr.FromString("sum(rate(http_requests_total[5m]))"),
+				},
+			})
+			Expect(err).To(BeNil())
+
+			registeredRules := or.ListRecordingRules()
+			Expect(registeredRules).To(HaveLen(1))
+			Expect(registeredRules[0].MetricsOpts.ConstLabels).To(Equal(map[string]string{
+				"controller":                  "guestbook",
+				"kubernetes_operator_part_of": "guestbook",
+			}))
+		})
+
+		It("should use the name prefix and common labels of the registry options", func() {
+			or = operatorrules.NewRegistry(
+				operatorrules.WithNamePrefix("guestbook_operator_"),
+				operatorrules.WithCommonLabels(map[string]string{"kubernetes_operator_part_of": "guestbook"}),
+			)
+			err := or.RegisterRecordingRules([]operatorrules.RecordingRule{
+				{
+					MetricsOpts: operatormetrics.MetricOpts{Name: "ready_pods"},
+					Expr:        intstr.FromString("sum(kube_pod_status_ready)"),
+				},
+			})
+			Expect(err).To(BeNil())
+
+			registeredRules := or.ListRecordingRules()
+			Expect(registeredRules).To(HaveLen(1))
+			Expect(registeredRules[0].MetricsOpts.Name).To(Equal("guestbook_operator_ready_pods"))
+			Expect(registeredRules[0].MetricsOpts.ConstLabels).To(Equal(map[string]string{
+				"kubernetes_operator_part_of": "guestbook",
+			}))
+		})
+
 		It("should replace recording rule with the same name and expression", func() {
 			recordingRules := []operatorrules.RecordingRule{
 				{
@@ -121,6 +189,86 @@ var _ = Describe("OperatorRules", func() {
 			Expect(registeredAlerts).To(ConsistOf(alerts))
 		})
 
+		It("should add the common const labels to alerts, with per alert override", func() {
+			or = operatorrules.NewRegistry(operatorrules.WithCommonLabels(map[string]string{
+				"kubernetes_operator_part_of":   "guestbook",
+				"kubernetes_operator_component": "guestbook-operator",
+			}))
+
+			alertLabels := map[string]string{
+				"severity":                      "critical",
+				"kubernetes_operator_component": "guestbook-webhook",
+			}
+			err := or.RegisterAlerts([]promv1.Rule{
+				{
+					Alert:  "ExampleAlert1",
+					Expr:   intstr.FromString("sum(rate(http_requests_total[1m])) > 100"),
+					Labels: alertLabels,
+				},
+			})
+			Expect(err).To(BeNil())
+
+			registeredAlerts := or.ListAlerts()
+			Expect(registeredAlerts).To(HaveLen(1))
+			Expect(registeredAlerts[0].Labels).To(Equal(map[string]string{
+				"severity":                      "critical",
+				"kubernetes_operator_part_of":   "guestbook",
+				"kubernetes_operator_component": "guestbook-webhook",
+			}))
+			Expect(alertLabels).To(HaveLen(2))
+		})
+
+		It("should not add common labels when the registry sets none", func() {
+			err := or.RegisterAlerts([]promv1.Rule{
+				{
+					Alert:  "ExampleAlert1",
+					Expr:   intstr.FromString("sum(rate(http_requests_total[1m])) > 100"),
+					Labels: map[string]string{"severity": "critical"},
+				},
+			})
+			Expect(err).To(BeNil())
+
+			registeredAlerts := or.ListAlerts()
+			Expect(registeredAlerts).To(HaveLen(1))
+			Expect(registeredAlerts[0].Labels).To(Equal(map[string]string{"severity": "critical"}))
+		})
+
+		It("should set the runbook URL of the alerts without one from the registry base URL", func() {
+			or = operatorrules.NewRegistry(operatorrules.WithRunbookBaseURL("https://example.com/runbooks/"))
+
+			customAnnotations := map[string]string{"runbook_url": "https://example.com/custom"}
+			otherAnnotations := map[string]string{"summary": "Example alert 2"}
+			err := or.RegisterAlerts([]promv1.Rule{
+				{
+					Alert:       "ExampleAlert1",
+					Expr:        intstr.FromString("sum(rate(http_requests_total[1m])) > 100"),
+					Annotations: customAnnotations,
+				},
+				{
+					Alert:       "ExampleAlert2",
+					Expr:        intstr.FromString("sum(rate(http_requests_total[1m])) > 200"),
+					Annotations: otherAnnotations,
+				},
+				{
+					Alert: "ExampleAlert3",
+					Expr:  intstr.FromString("sum(rate(http_requests_total[1m])) > 300"),
+				},
+			})
+			Expect(err).To(BeNil())
+
+			registeredAlerts := or.ListAlerts()
+			Expect(registeredAlerts).To(HaveLen(3))
+			Expect(registeredAlerts[0].Annotations).To(Equal(customAnnotations))
+			Expect(registeredAlerts[1].Annotations).To(Equal(map[string]string{
+				"summary":     "Example alert 2",
+				"runbook_url": "https://example.com/runbooks/ExampleAlert2",
+			}))
+			Expect(registeredAlerts[2].Annotations).To(Equal(map[string]string{
+				"runbook_url": "https://example.com/runbooks/ExampleAlert3",
+			}))
+			Expect(otherAnnotations).To(HaveLen(1))
+		})
+
 		It("should replace alerts with the same name and same expression in the same RegisterAlerts call", func() {
 			alerts := []promv1.Rule{
 				{
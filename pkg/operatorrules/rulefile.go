@@ -0,0 +1,125 @@
+package operatorrules
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+)
+
+// RuleFile represents a Prometheus rule file, as loaded by Prometheus through
+// the rule_files configuration and checked by `promtool check rules`.
+type RuleFile struct {
+	Groups []promv1.RuleGroup `json:"groups"`
+}
+
+// BuildRuleFile returns the registered recording rules and alerts as a plain
+// Prometheus rule file in YAML format, for consumers not running the
+// prometheus-operator.
+func (r *Registry) BuildRuleFile() ([]byte, error) {
+	spec, err := r.buildPrometheusRuleSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(RuleFile{Groups: spec.Groups})
+}
+
+const (
+	recordingRulesGroupName = "recordingRules.rules"
+	alertsGroupName         = "alerts.rules"
+)
+
+// ImportRuleFile registers the recording rules and alerts of the given
+// Prometheus rule file, in YAML format. The file must have the layout built
+// by BuildRuleFile: recording rules in the "recordingRules.rules" group,
+// alerts in the "alerts.rules" group, and no group settings such as interval
+// or limit, which the registry cannot keep. The rules are registered as they
+// are in the file, without the name prefix and common labels of the registry,
+// as a file built by BuildRuleFile already has them. Nothing is registered
+// when the file is invalid.
+func (r *Registry) ImportRuleFile(data []byte) error {
+	var ruleFile RuleFile
+	if err := yaml.UnmarshalStrict(data, &ruleFile); err != nil {
+		return fmt.Errorf("failed to parse rule file: %w", err)
+	}
+
+	var recordingRules []RecordingRule
+	var alerts []promv1.Rule
+
+	for _, group := range ruleFile.Groups {
+		if err := validateRuleFileGroup(group); err != nil {
+			return fmt.Errorf("invalid group %s: %w", group.Name, err)
+		}
+
+		for _, rule := range group.Rules {
+			if err := validateRuleFileRule(group.Name, rule); err != nil {
+				return fmt.Errorf("invalid rule in group %s: %w", group.Name, err)
+			}
+
+			if rule.Record != "" {
+				recordingRules = append(recordingRules, RecordingRule{
+					MetricsOpts: operatormetrics.MetricOpts{
+						Name:        rule.Record,
+						ConstLabels: rule.Labels,
+					},
+					Expr: rule.Expr,
+				})
+			} else {
+				alerts = append(alerts, rule)
+			}
+		}
+	}
+
+	for _, recordingRule := range recordingRules {
+		r.registerRecordingRule(recordingRule)
+	}
+	for _, alert := range alerts {
+		r.registerAlert(alert)
+	}
+
+	return nil
+}
+
+func validateRuleFileGroup(group promv1.RuleGroup) error {
+	if group.Name != recordingRulesGroupName && group.Name != alertsGroupName {
+		return fmt.Errorf("only the %s and %s groups are supported", recordingRulesGroupName, alertsGroupName)
+	}
+
+	if group.Interval != nil || group.Limit != nil || group.PartialResponseStrategy != "" {
+		return fmt.Errorf("'interval', 'limit' and 'partial_response_strategy' are not supported")
+	}
+
+	return nil
+}
+
+func validateRuleFileRule(groupName string, rule promv1.Rule) error {
+	if rule.Record == "" && rule.Alert == "" {
+		return fmt.Errorf("one of 'record' or 'alert' must be set")
+	}
+
+	if rule.Record != "" && rule.Alert != "" {
+		return fmt.Errorf("only one of 'record' and 'alert' must be set, got %s and %s", rule.Record, rule.Alert)
+	}
+
+	if rule.Expr.String() == "" {
+		return fmt.Errorf("rule %s%s has no expression", rule.Record, rule.Alert)
+	}
+
+	if rule.Record != "" && (rule.For != nil || len(rule.Annotations) != 0) {
+		return fmt.Errorf("recording rule %s must not set 'for' or 'annotations'", rule.Record)
+	}
+
+	if rule.Record != "" && groupName != recordingRulesGroupName {
+		return fmt.Errorf("recording rule %s must be in the %s group", rule.Record, recordingRulesGroupName)
+	}
+
+	if rule.Alert != "" && groupName != alertsGroupName {
+		return fmt.Errorf("alert %s must be in the %s group", rule.Alert, alertsGroupName)
+	}
+
+	return nil
+}
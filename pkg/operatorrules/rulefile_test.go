@@ -0,0 +1,154 @@
+package operatorrules_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+)
+
+const ruleFile = `groups:
+- name: recordingRules.rules
+  rules:
+  - expr: sum(up{namespace='default', pod=~'guestbook-operator-.*'}) or vector(0)
+    labels:
+      controller: guestbook
+    record: number_of_pods
+- name: alerts.rules
+  rules:
+  - alert: GuestbookOperatorDown
+    annotations:
+      summary: Guestbook operator is down
+    expr: number_of_pods == 0
+    for: 5m
+    labels:
+      severity: critical
+`
+
+var _ = Describe("RuleFile", func() {
+	var or *operatorrules.Registry
+
+	BeforeEach(func() {
+		or = operatorrules.NewRegistry()
+	})
+
+	Context("BuildRuleFile", func() {
+		It("should export the registered rules as a Prometheus rule file", func() {
+			err := or.RegisterRecordingRules([]operatorrules.RecordingRule{
+				{
+					MetricsOpts: operatormetrics.MetricOpts{
+						Name:        "number_of_pods",
+						ConstLabels: map[string]string{"controller": "guestbook"},
+					},
+					MetricType: operatormetrics.GaugeType,
+					Expr:       intstr.FromString("sum(up{namespace='default', pod=~'guestbook-operator-.*'}) or vector(0)"),
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = or.RegisterAlerts([]promv1.Rule{
+				{
+					Alert:       "GuestbookOperatorDown",
+					Expr:        intstr.FromString("number_of_pods == 0"),
+					For:         ptr.To(promv1.Duration("5m")),
+					Annotations: map[string]string{"summary": "Guestbook operator is down"},
+					Labels:      map[string]string{"severity": "critical"},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			data, err := or.BuildRuleFile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(ruleFile))
+		})
+
+		It("should fail when there are no registered rules", func() {
+			_, err := or.BuildRuleFile()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("ImportRuleFile", func() {
+		It("should register the rules of a Prometheus rule file", func() {
+			err := or.ImportRuleFile([]byte(ruleFile))
+			Expect(err).ToNot(HaveOccurred())
+
+			recordingRules := or.ListRecordingRules()
+			Expect(recordingRules).To(HaveLen(1))
+			Expect(recordingRules[0].MetricsOpts.Name).To(Equal("number_of_pods"))
+			Expect(recordingRules[0].MetricsOpts.ConstLabels).To(HaveKeyWithValue("controller", "guestbook"))
+
+			alerts := or.ListAlerts()
+			Expect(alerts).To(HaveLen(1))
+			Expect(alerts[0].Alert).To(Equal("GuestbookOperatorDown"))
+			Expect(*alerts[0].For).To(Equal(promv1.Duration("5m")))
+		})
+
+		It("should round trip the exported rule file", func() {
+			Expect(or.ImportRuleFile([]byte(ruleFile))).To(Succeed())
+
+			data, err := or.BuildRuleFile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(ruleFile))
+		})
+
+		It("should register the rules as-is, without the name prefix and common labels", func() {
+			or = operatorrules.NewRegistry(
+				operatorrules.WithNamePrefix("guestbook"),
+				operatorrules.WithCommonLabels(map[string]string{"team": "guestbook"}),
+			)
+			Expect(or.ImportRuleFile([]byte(ruleFile))).To(Succeed())
+
+			data, err := or.BuildRuleFile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(ruleFile))
+		})
+
+		It("should fail on unknown fields", func() {
+			err := or.ImportRuleFile([]byte("groups:\n- name: alerts.rules\n  rules:\n  - alert: A\n    expr: up\n    severity: critical\n"))
+			Expect(err).To(MatchError(ContainSubstring("failed to parse rule file")))
+		})
+
+		It("should fail on rules without record or alert", func() {
+			err := or.ImportRuleFile([]byte("groups:\n- name: alerts.rules\n  rules:\n  - expr: up\n"))
+			Expect(err).To(MatchError(ContainSubstring("one of 'record' or 'alert' must be set")))
+		})
+
+		It("should fail on rules without expression", func() {
+			err := or.ImportRuleFile([]byte("groups:\n- name: recordingRules.rules\n  rules:\n  - record: a\n    expr: \"\"\n"))
+			Expect(err).To(MatchError(ContainSubstring("has no expression")))
+		})
+
+		It("should fail on groups other than the ones built by BuildRuleFile", func() {
+			err := or.ImportRuleFile([]byte("groups:\n- name: custom\n  rules:\n  - alert: A\n    expr: up\n"))
+			Expect(err).To(MatchError(ContainSubstring("only the recordingRules.rules and alerts.rules groups are supported")))
+		})
+
+		It("should fail on rules in the wrong group", func() {
+			err := or.ImportRuleFile([]byte("groups:\n- name: recordingRules.rules\n  rules:\n  - alert: A\n    expr: up\n"))
+			Expect(err).To(MatchError(ContainSubstring("alert A must be in the alerts.rules group")))
+		})
+
+		It("should fail on group settings", func() {
+			err := or.ImportRuleFile([]byte("groups:\n- name: alerts.rules\n  interval: 1m\n  rules:\n  - alert: A\n    expr: up\n"))
+			Expect(err).To(MatchError(ContainSubstring("'interval', 'limit' and 'partial_response_strategy' are not supported")))
+
+			err = or.ImportRuleFile([]byte("groups:\n- name: alerts.rules\n  limit: 10\n  rules:\n  - alert: A\n    expr: up\n"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should not register any rule when an alert is invalid", func() {
+			err := or.ImportRuleFile([]byte(ruleFile + "  - alert: B\n    expr: \"\"\n"))
+			Expect(err).To(MatchError(ContainSubstring("rule B has no expression")))
+
+			Expect(or.ListRecordingRules()).To(BeEmpty())
+			Expect(or.ListAlerts()).To(BeEmpty())
+		})
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	promv1alpha1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1alpha1"
 )
 
 func AddToScheme(scheme *runtime.Scheme) error {
@@ -13,6 +14,11 @@ func AddToScheme(scheme *runtime.Scheme) error {
 		return err
 	}
 
+	err = promv1alpha1.AddToScheme(scheme)
+	if err != nil {
+		return err
+	}
+
 	err = rbacv1.AddToScheme(scheme)
 	if err != nil {
 		return err
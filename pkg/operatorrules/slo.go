@@ -0,0 +1,255 @@
+package operatorrules
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+)
+
+// DefaultSLOWindow is the window of an SLO without Window.
+const DefaultSLOWindow = "30d"
+
+// SLI is a service level indicator, as the ratio of good events to total
+// events. Both are PromQL selectors of counters, such as
+// guestbook_operator_reconcile_count{result="success"}.
+type SLI struct {
+	GoodEvents  string
+	TotalEvents string
+}
+
+// CounterSLI returns an SLI whose total events are counted by the given
+// counter, and good events by its series matching the given label matchers,
+// such as `result="success"`.
+func CounterSLI(counter operatormetrics.Metric, goodMatchers string) SLI {
+	name := counter.GetOpts().FullName()
+
+	return SLI{
+		GoodEvents:  fmt.Sprintf("%s{%s}", name, goodMatchers),
+		TotalEvents: name,
+	}
+}
+
+// HistogramSLI returns an SLI whose total events are the observations of the
+// given histogram, and good events the observations less than or equal to
+// the given bucket upper bound, such as "0.5".
+func HistogramSLI(histogram operatormetrics.Metric, le string) SLI {
+	name := histogram.GetOpts().FullName()
+
+	return SLI{
+		GoodEvents:  fmt.Sprintf("%s_bucket{le=%q}", name, le),
+		TotalEvents: name + "_count",
+	}
+}
+
+// SLO is a service level objective, generating the error ratio recording
+// rules of its SLI and multi-window multi-burn-rate alerts, as described in
+// the Google SRE workbook.
+type SLO struct {
+	// Name of the SLO, used as the metric part of the error ratio recording
+	// rules, as in slo:<name>_errors:ratio_rate5m.
+	Name string
+	// AlertName of the burn rate alerts, in PascalCase.
+	AlertName string
+	// Description of the SLO, used in the alert annotations.
+	Description string
+
+	SLI SLI
+	// Objective is the target ratio of good events, such as 0.999.
+	Objective float64
+	// Window is the period the Objective is measured over. Defaults to
+	// DefaultSLOWindow.
+	Window string
+	// RecordWindow also records the error ratio over the whole Window, such
+	// as for dashboards. It is not needed by the alerts and is costly to
+	// evaluate over long windows, so it is not recorded by default.
+	RecordWindow bool
+
+	// HealthImpact is set as the operator_health_impact label of the alerts.
+	HealthImpact HealthImpact
+	// AlertLabels are added to the labels of the alerts.
+	AlertLabels map[string]string
+	// RunbookURL is set as the runbook_url annotation of the alerts. Defaults
+	// to the runbook base URL of the registry followed by the alert name.
+	RunbookURL string
+}
+
+// burnRateAlert is a pair of windows consuming a given share of the error
+// budget of a 30 days window, as recommended by the Google SRE workbook.
+type burnRateAlert struct {
+	severity    Severity
+	budget      float64
+	longWindow  string
+	shortWindow string
+}
+
+var burnRateAlerts = []burnRateAlert{
+	{severity: SeverityCritical, budget: 0.02, longWindow: "1h", shortWindow: "5m"},
+	{severity: SeverityCritical, budget: 0.05, longWindow: "6h", shortWindow: "30m"},
+	{severity: SeverityWarning, budget: 0.1, longWindow: "1d", shortWindow: "2h"},
+	{severity: SeverityWarning, budget: 0.1, longWindow: "3d", shortWindow: "6h"},
+}
+
+// Build returns the error ratio recording rules and the burn rate alerts of
+// the SLO. The alerts refer to the recording rules without a name prefix, so
+// SLOs registered with a name prefix must use Registry.RegisterSLOs.
+func (s SLO) Build() ([]RecordingRule, []promv1.Rule, error) {
+	return s.build("")
+}
+
+// build works as Build, with the alerts referring to the recording rules
+// prefixed with the given name prefix.
+func (s SLO) build(namePrefix string) ([]RecordingRule, []promv1.Rule, error) {
+	window, err := s.validate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid SLO %s: %w", s.Name, err)
+	}
+
+	recordingRules, err := RecordingRuleTemplate{
+		MetricsOpts: operatormetrics.MetricOpts{
+			Name: s.Name + "_errors",
+			Help: fmt.Sprintf("Error ratio of the %s SLO", s.Name),
+		},
+		MetricType: operatormetrics.GaugeType,
+		Level:      "slo",
+		Operation:  "ratio_rate",
+		Windows:    s.windows(),
+		Expr: fmt.Sprintf("1 - (sum(rate(%s[{{.Window}}])) / sum(rate(%s[{{.Window}}])))",
+			s.SLI.GoodEvents, s.SLI.TotalEvents),
+	}.Build()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var alerts []promv1.Rule
+	for _, severity := range []Severity{SeverityCritical, SeverityWarning} {
+		alert, err := s.buildAlert(severity, window, namePrefix)
+		if err != nil {
+			return nil, nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return recordingRules, alerts, nil
+}
+
+func (s SLO) buildAlert(severity Severity, window time.Duration, namePrefix string) (promv1.Rule, error) {
+	var conditions []string
+	for _, bra := range burnRateAlerts {
+		if bra.severity != severity {
+			continue
+		}
+
+		longWindow, _ := model.ParseDuration(bra.longWindow)
+		factor := strconv.FormatFloat(bra.budget*float64(window)/float64(longWindow), 'f', -1, 64)
+		threshold := fmt.Sprintf("(%s * (1 - %s))", factor, strconv.FormatFloat(s.Objective, 'f', -1, 64))
+
+		conditions = append(conditions, fmt.Sprintf("(%s > %s and %s > %s)",
+			s.errorRatioRecord(namePrefix, bra.longWindow), threshold,
+			s.errorRatioRecord(namePrefix, bra.shortWindow), threshold))
+	}
+
+	return Alert{
+		Name:     s.AlertName,
+		Expr:     strings.Join(conditions, " or "),
+		Severity: severity,
+		Summary:  fmt.Sprintf("%s SLO is burning its error budget too fast", s.Name),
+		Description: fmt.Sprintf("%s is consuming the error budget of its %s objective over %s too fast.",
+			s.description(), strconv.FormatFloat(s.Objective*100, 'f', -1, 64)+"%", s.window()),
+		RunbookURL:   s.RunbookURL,
+		HealthImpact: s.HealthImpact,
+		Labels:       s.AlertLabels,
+	}.Build()
+}
+
+func (s SLO) validate() (time.Duration, error) {
+	if s.Name == "" || !model.IsValidMetricName(model.LabelValue(s.Name)) {
+		return 0, fmt.Errorf("name must be a valid metric name")
+	}
+
+	if s.AlertName == "" {
+		return 0, fmt.Errorf("alert name must be set")
+	}
+
+	if s.SLI.GoodEvents == "" || s.SLI.TotalEvents == "" {
+		return 0, fmt.Errorf("SLI must have good and total events")
+	}
+
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return 0, fmt.Errorf("objective must be between 0 and 1, got %v", s.Objective)
+	}
+
+	window, err := model.ParseDuration(s.window())
+	if err != nil {
+		return 0, fmt.Errorf("invalid window %q: %w", s.window(), err)
+	}
+
+	return time.Duration(window), nil
+}
+
+func (s SLO) window() string {
+	if s.Window == "" {
+		return DefaultSLOWindow
+	}
+	return s.Window
+}
+
+func (s SLO) description() string {
+	if s.Description == "" {
+		return s.Name
+	}
+	return s.Description
+}
+
+// windows returns the windows of the burn rate alerts, and the SLO window
+// when recorded, from the shortest to the longest.
+func (s SLO) windows() []string {
+	var windows []string
+	if s.RecordWindow {
+		windows = append(windows, s.window())
+	}
+	for _, bra := range burnRateAlerts {
+		windows = append(windows, bra.shortWindow, bra.longWindow)
+	}
+
+	slices.SortFunc(windows, func(a, b string) int {
+		da, _ := model.ParseDuration(a)
+		db, _ := model.ParseDuration(b)
+		return cmp.Compare(da, db)
+	})
+
+	return slices.Compact(windows)
+}
+
+func (s SLO) errorRatioRecord(namePrefix, window string) string {
+	return operatormetrics.MetricOpts{Name: fmt.Sprintf("slo:%s_errors:ratio_rate%s", s.Name, window)}.FullNameWithPrefix(namePrefix)
+}
+
+// RegisterSLOs registers the recording rules and alerts generated by the
+// given SLOs.
+func (r *Registry) RegisterSLOs(slos ...SLO) error {
+	for _, slo := range slos {
+		recordingRules, alerts, err := slo.build(r.namePrefix)
+		if err != nil {
+			return err
+		}
+
+		if err := r.RegisterRecordingRules(recordingRules); err != nil {
+			return err
+		}
+
+		if err := r.RegisterAlerts(alerts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package operatorrules_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("SLO", func() {
+	var reconcileSLO operatorrules.SLO
+
+	BeforeEach(func() {
+		reconcileCount := operatormetrics.NewCounterVec(operatormetrics.MetricOpts{
+			Name: "guestbook_operator_reconcile_count",
+			Help: "Number of reconciles",
+		}, []string{"result"})
+
+		reconcileSLO = operatorrules.SLO{
+			Name:         "guestbook_operator_reconcile_availability",
+			AlertName:    "GuestbookOperatorReconcileErrorBudgetBurn",
+			Description:  "Guestbook operator reconcile availability",
+			SLI:          operatorrules.CounterSLI(reconcileCount, `result="success"`),
+			Objective:    0.999,
+			HealthImpact: operatorrules.HealthImpactWarning,
+			RunbookURL:   "https://example.com/runbooks/GuestbookOperatorReconcileErrorBudgetBurn.md",
+		}
+	})
+
+	It("should build the error ratio recording rules for the standard windows", func() {
+		recordingRules, _, err := reconcileSLO.Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		var names []string
+		for _, rr := range recordingRules {
+			names = append(names, rr.MetricsOpts.Name)
+		}
+		Expect(names).To(Equal([]string{
+			"slo:guestbook_operator_reconcile_availability_errors:ratio_rate5m",
+			"slo:guestbook_operator_reconcile_availability_errors:ratio_rate30m",
+			"slo:guestbook_operator_reconcile_availability_errors:ratio_rate1h",
+			"slo:guestbook_operator_reconcile_availability_errors:ratio_rate2h",
+			"slo:guestbook_operator_reconcile_availability_errors:ratio_rate6h",
+			"slo:guestbook_operator_reconcile_availability_errors:ratio_rate1d",
+			"slo:guestbook_operator_reconcile_availability_errors:ratio_rate3d",
+		}))
+		Expect(recordingRules[0].Expr.String()).To(Equal(
+			`1 - (sum(rate(guestbook_operator_reconcile_count{result="success"}[5m])) / ` +
+				`sum(rate(guestbook_operator_reconcile_count[5m])))`))
+	})
+
+	It("should record the error ratio over the SLO window when enabled", func() {
+		reconcileSLO.RecordWindow = true
+
+		recordingRules, _, err := reconcileSLO.Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recordingRules).To(HaveLen(8))
+		Expect(recordingRules[7].MetricsOpts.Name).To(Equal(
+			"slo:guestbook_operator_reconcile_availability_errors:ratio_rate30d"))
+	})
+
+	It("should build multi-window multi-burn-rate alerts", func() {
+		_, alerts, err := reconcileSLO.Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(alerts).To(HaveLen(2))
+
+		critical := alerts[0]
+		Expect(critical.Alert).To(Equal("GuestbookOperatorReconcileErrorBudgetBurn"))
+		Expect(critical.Labels).To(Equal(map[string]string{"severity": "critical", "operator_health_impact": "warning"}))
+		Expect(critical.Expr.String()).To(Equal(
+			"(slo:guestbook_operator_reconcile_availability_errors:ratio_rate1h > (14.4 * (1 - 0.999)) and " +
+				"slo:guestbook_operator_reconcile_availability_errors:ratio_rate5m > (14.4 * (1 - 0.999))) or " +
+				"(slo:guestbook_operator_reconcile_availability_errors:ratio_rate6h > (6 * (1 - 0.999)) and " +
+				"slo:guestbook_operator_reconcile_availability_errors:ratio_rate30m > (6 * (1 - 0.999)))"))
+		Expect(critical.Annotations).To(HaveKeyWithValue("runbook_url", reconcileSLO.RunbookURL))
+		Expect(critical.Annotations["description"]).To(Equal(
+			"Guestbook operator reconcile availability is consuming the error budget of its 99.9% objective over 30d too fast."))
+
+		warning := alerts[1]
+		Expect(warning.Labels).To(HaveKeyWithValue("severity", "warning"))
+		Expect(warning.Expr.String()).To(Equal(
+			"(slo:guestbook_operator_reconcile_availability_errors:ratio_rate1d > (3 * (1 - 0.999)) and " +
+				"slo:guestbook_operator_reconcile_availability_errors:ratio_rate2h > (3 * (1 - 0.999))) or " +
+				"(slo:guestbook_operator_reconcile_availability_errors:ratio_rate3d > (1 * (1 - 0.999)) and " +
+				"slo:guestbook_operator_reconcile_availability_errors:ratio_rate6h > (1 * (1 - 0.999)))"))
+	})
+
+	It("should scale the burn rates to the SLO window", func() {
+		reconcileSLO.Window = "7d"
+
+		_, alerts, err := reconcileSLO.Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(alerts[0].Expr.String()).To(ContainSubstring("ratio_rate1h > (3.36 * (1 - 0.999))"))
+	})
+
+	It("should build latency SLIs from histogram buckets", func() {
+		histogram := operatormetrics.NewHistogram(operatormetrics.MetricOpts{
+			Name: "guestbook_operator_reconcile_duration_seconds",
+		}, prometheus.HistogramOpts{})
+
+		sli := operatorrules.HistogramSLI(histogram, "0.5")
+		Expect(sli.GoodEvents).To(Equal(`guestbook_operator_reconcile_duration_seconds_bucket{le="0.5"}`))
+		Expect(sli.TotalEvents).To(Equal("guestbook_operator_reconcile_duration_seconds_count"))
+	})
+
+	It("should register rules passing the default linter", func() {
+		registry := operatorrules.NewRegistry()
+		Expect(registry.RegisterSLOs(reconcileSLO)).To(Succeed())
+
+		Expect(registry.ListRecordingRules()).To(HaveLen(7))
+		Expect(registry.ListAlerts()).To(HaveLen(2))
+
+		linter := testutil.New()
+		Expect(linter.LintRecordingRules(registry.ListRecordingRules())).To(BeEmpty())
+		Expect(linter.LintAlerts(registry.ListAlerts())).To(BeEmpty())
+	})
+
+	It("should refer to the recording rules with the name prefix of the registry", func() {
+		reconcileSLO.Name = "reconcile_availability"
+
+		registry := operatorrules.NewRegistry(operatorrules.WithNamePrefix("guestbook_operator_"))
+		Expect(registry.RegisterSLOs(reconcileSLO)).To(Succeed())
+
+		Expect(registry.ListRecordingRules()[0].MetricsOpts.Name).To(
+			HavePrefix("slo:guestbook_operator_reconcile_availability_errors:"))
+		for _, alert := range registry.ListAlerts() {
+			Expect(alert.Expr.String()).To(HavePrefix("(slo:guestbook_operator_reconcile_availability_errors:ratio_rate"))
+		}
+	})
+
+	DescribeTable("should fail to build invalid SLOs", func(mutate func(*operatorrules.SLO), errMsg string) {
+		mutate(&reconcileSLO)
+		_, _, err := reconcileSLO.Build()
+		Expect(err).To(MatchError(ContainSubstring(errMsg)))
+	},
+		Entry("with invalid name", func(s *operatorrules.SLO) { s.Name = "reconcile-availability" }, "name must be a valid metric name"),
+		Entry("without alert name", func(s *operatorrules.SLO) { s.AlertName = "" }, "alert name must be set"),
+		Entry("without SLI", func(s *operatorrules.SLO) { s.SLI = operatorrules.SLI{} }, "SLI must have good and total events"),
+		Entry("with invalid objective", func(s *operatorrules.SLO) { s.Objective = 99.9 }, "objective must be between 0 and 1"),
+		Entry("with invalid window", func(s *operatorrules.SLO) { s.Window = "a month" }, `invalid window "a month"`),
+	)
+})
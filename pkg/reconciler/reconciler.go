@@ -0,0 +1,264 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+)
+
+// Client is the subset of the controller-runtime client used to reconcile
+// the observability resources. Any client.Client, including the fake client
+// from controller-runtime, satisfies it.
+type Client interface {
+	Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error
+	Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error
+	Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error
+	Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error
+	Scheme() *runtime.Scheme
+}
+
+// Reconciler creates or updates the objects built by operatorrules in the
+// cluster. Only the fields set by the builders are managed, any other label,
+// annotation or owner reference present in the cluster is preserved.
+type Reconciler struct {
+	client Client
+	owner  client.Object
+}
+
+// New creates a new Reconciler. When owner is not nil, it is set as the
+// controller owner reference of every reconciled object, so it must be
+// cluster-scoped or live in the same namespace as the reconciled objects.
+func New(c Client, owner client.Object) *Reconciler {
+	return &Reconciler{
+		client: c,
+		owner:  owner,
+	}
+}
+
+// Reconcile creates or updates the given objects in order. It returns true
+// if any of them was created or changed.
+func (r *Reconciler) Reconcile(ctx context.Context, objs ...client.Object) (bool, error) {
+	changed := false
+
+	for _, obj := range objs {
+		objChanged, err := r.reconcileObject(ctx, obj)
+		if err != nil {
+			return changed, err
+		}
+		changed = changed || objChanged
+	}
+
+	return changed, nil
+}
+
+// ReconcilePrometheusRule creates or updates the given PrometheusRule. It
+// returns true if the object was created or changed.
+func (r *Reconciler) ReconcilePrometheusRule(ctx context.Context, desired *promv1.PrometheusRule) (bool, error) {
+	return reconcile(ctx, r, desired, &promv1.PrometheusRule{}, func(existing, desired *promv1.PrometheusRule) {
+		existing.Spec = desired.Spec
+	})
+}
+
+// ReconcileServiceMonitor creates or updates the given ServiceMonitor. It
+// returns true if the object was created or changed.
+func (r *Reconciler) ReconcileServiceMonitor(ctx context.Context, desired *promv1.ServiceMonitor) (bool, error) {
+	return reconcile(ctx, r, desired, &promv1.ServiceMonitor{}, func(existing, desired *promv1.ServiceMonitor) {
+		existing.Spec = desired.Spec
+	})
+}
+
+// ReconcilePodMonitor creates or updates the given PodMonitor. It returns
+// true if the object was created or changed.
+func (r *Reconciler) ReconcilePodMonitor(ctx context.Context, desired *promv1.PodMonitor) (bool, error) {
+	return reconcile(ctx, r, desired, &promv1.PodMonitor{}, func(existing, desired *promv1.PodMonitor) {
+		existing.Spec = desired.Spec
+	})
+}
+
+// ReconcileRole creates or updates the given Role. It returns true if the
+// object was created or changed.
+func (r *Reconciler) ReconcileRole(ctx context.Context, desired *rbacv1.Role) (bool, error) {
+	return reconcile(ctx, r, desired, &rbacv1.Role{}, func(existing, desired *rbacv1.Role) {
+		existing.Rules = desired.Rules
+	})
+}
+
+// ReconcileRoleBinding creates or updates the given RoleBinding. Since the
+// role reference of a RoleBinding is immutable, the object is recreated when
+// it changes. It returns true if the object was created or changed.
+func (r *Reconciler) ReconcileRoleBinding(ctx context.Context, desired *rbacv1.RoleBinding) (bool, error) {
+	existing := &rbacv1.RoleBinding{}
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(desired), existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+
+	if err == nil && existing.RoleRef != desired.RoleRef {
+		if err := r.client.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+
+	return reconcile(ctx, r, desired, &rbacv1.RoleBinding{}, func(existing, desired *rbacv1.RoleBinding) {
+		existing.Subjects = desired.Subjects
+	})
+}
+
+// ReconcileClusterRole creates or updates the given ClusterRole. It returns
+// true if the object was created or changed.
+func (r *Reconciler) ReconcileClusterRole(ctx context.Context, desired *rbacv1.ClusterRole) (bool, error) {
+	return reconcile(ctx, r, desired, &rbacv1.ClusterRole{}, func(existing, desired *rbacv1.ClusterRole) {
+		existing.Rules = desired.Rules
+	})
+}
+
+// ReconcileClusterRoleBinding creates or updates the given ClusterRoleBinding.
+// Since the role reference of a ClusterRoleBinding is immutable, the object is
+// recreated when it changes. It returns true if the object was created or
+// changed.
+func (r *Reconciler) ReconcileClusterRoleBinding(ctx context.Context, desired *rbacv1.ClusterRoleBinding) (bool, error) {
+	existing := &rbacv1.ClusterRoleBinding{}
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(desired), existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+
+	if err == nil && existing.RoleRef != desired.RoleRef {
+		if err := r.client.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+
+	return reconcile(ctx, r, desired, &rbacv1.ClusterRoleBinding{}, func(existing, desired *rbacv1.ClusterRoleBinding) {
+		existing.Subjects = desired.Subjects
+	})
+}
+
+// ReconcileRBAC creates or updates all the objects built by
+// operatorrules.BuildRBAC. It returns true if any of them was created or
+// changed.
+func (r *Reconciler) ReconcileRBAC(ctx context.Context, objs *operatorrules.RBACObjects) (bool, error) {
+	var list []client.Object
+
+	for _, o := range objs.ClusterRoles {
+		list = append(list, o)
+	}
+	for _, o := range objs.ClusterRoleBindings {
+		list = append(list, o)
+	}
+	for _, o := range objs.Roles {
+		list = append(list, o)
+	}
+	for _, o := range objs.RoleBindings {
+		list = append(list, o)
+	}
+
+	return r.Reconcile(ctx, list...)
+}
+
+func (r *Reconciler) reconcileObject(ctx context.Context, obj client.Object) (bool, error) {
+	switch o := obj.(type) {
+	case *promv1.PrometheusRule:
+		return r.ReconcilePrometheusRule(ctx, o)
+	case *promv1.ServiceMonitor:
+		return r.ReconcileServiceMonitor(ctx, o)
+	case *promv1.PodMonitor:
+		return r.ReconcilePodMonitor(ctx, o)
+	case *rbacv1.Role:
+		return r.ReconcileRole(ctx, o)
+	case *rbacv1.RoleBinding:
+		return r.ReconcileRoleBinding(ctx, o)
+	case *rbacv1.ClusterRole:
+		return r.ReconcileClusterRole(ctx, o)
+	case *rbacv1.ClusterRoleBinding:
+		return r.ReconcileClusterRoleBinding(ctx, o)
+	default:
+		return false, fmt.Errorf("unsupported object type %T", obj)
+	}
+}
+
+func reconcile[T client.Object](ctx context.Context, r *Reconciler, desired T, existing T, mutate func(existing, desired T)) (bool, error) {
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(desired), existing)
+	if errors.IsNotFound(err) {
+		return r.create(ctx, desired)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	original := existing.DeepCopyObject()
+
+	mergeMetadata(existing, desired)
+	if err := r.setOwner(existing); err != nil {
+		return false, err
+	}
+	mutate(existing, desired)
+
+	if equality.Semantic.DeepEqual(original, existing) {
+		return false, nil
+	}
+
+	if err := r.client.Update(ctx, existing); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *Reconciler) create(ctx context.Context, desired client.Object) (bool, error) {
+	obj, ok := desired.DeepCopyObject().(client.Object)
+	if !ok {
+		return false, fmt.Errorf("failed to copy object %s", desired.GetName())
+	}
+
+	if err := r.setOwner(obj); err != nil {
+		return false, err
+	}
+
+	if err := r.client.Create(ctx, obj); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *Reconciler) setOwner(obj client.Object) error {
+	if r.owner == nil {
+		return nil
+	}
+
+	return controllerutil.SetControllerReference(r.owner, obj, r.client.Scheme())
+}
+
+// mergeMetadata copies the labels and annotations of desired into existing,
+// keeping the ones that are only present in existing.
+func mergeMetadata(existing, desired client.Object) {
+	existing.SetLabels(mergeMaps(existing.GetLabels(), desired.GetLabels()))
+	existing.SetAnnotations(mergeMaps(existing.GetAnnotations(), desired.GetAnnotations()))
+}
+
+func mergeMaps(existing, desired map[string]string) map[string]string {
+	if len(desired) == 0 {
+		return existing
+	}
+
+	result := make(map[string]string, len(existing)+len(desired))
+	for k, v := range existing {
+		result[k] = v
+	}
+	for k, v := range desired {
+		result[k] = v
+	}
+
+	return result
+}
@@ -0,0 +1,194 @@
+package reconciler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
+	"github.com/machadovilaca/operator-observability/pkg/reconciler"
+)
+
+var _ = Describe("Reconciler", func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		owner      *corev1.ConfigMap
+		rule       *promv1.PrometheusRule
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(operatorrules.AddToScheme(scheme)).To(Succeed())
+
+		owner = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "guestbook-operator",
+				Namespace: "default",
+				UID:       "a-uid",
+			},
+		}
+
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(owner).Build()
+
+		or := operatorrules.NewRegistry()
+		err := or.RegisterAlerts([]promv1.Rule{
+			{
+				Alert:  "GuestbookOperatorDown",
+				Expr:   intstr.FromString("number_of_pods == 0"),
+				Labels: map[string]string{"severity": "critical"},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		rule, err = or.BuildPrometheusRule("guestbook-rules", "default", map[string]string{"app": "guestbook"})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("PrometheusRule", func() {
+		It("should create the object with an owner reference", func() {
+			changed, err := reconciler.New(fakeClient, owner).ReconcilePrometheusRule(ctx, rule)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+
+			created := &promv1.PrometheusRule{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(rule), created)).To(Succeed())
+			Expect(created.Spec).To(Equal(rule.Spec))
+			Expect(created.OwnerReferences).To(HaveLen(1))
+			Expect(created.OwnerReferences[0].Name).To(Equal("guestbook-operator"))
+			Expect(*created.OwnerReferences[0].Controller).To(BeTrue())
+		})
+
+		It("should not report changes when the object is up to date", func() {
+			r := reconciler.New(fakeClient, owner)
+
+			_, err := r.ReconcilePrometheusRule(ctx, rule)
+			Expect(err).ToNot(HaveOccurred())
+
+			changed, err := r.ReconcilePrometheusRule(ctx, rule)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeFalse())
+		})
+
+		It("should update the spec and preserve fields it does not own", func() {
+			r := reconciler.New(fakeClient, nil)
+
+			_, err := r.ReconcilePrometheusRule(ctx, rule)
+			Expect(err).ToNot(HaveOccurred())
+
+			existing := &promv1.PrometheusRule{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(rule), existing)).To(Succeed())
+			existing.Labels["external"] = "value"
+			existing.Annotations = map[string]string{"external": "value"}
+			existing.Spec.Groups[0].Rules[0].Expr = intstr.FromString("vector(1)")
+			Expect(fakeClient.Update(ctx, existing)).To(Succeed())
+
+			changed, err := r.ReconcilePrometheusRule(ctx, rule)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+
+			updated := &promv1.PrometheusRule{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(rule), updated)).To(Succeed())
+			Expect(updated.Spec).To(Equal(rule.Spec))
+			Expect(updated.Labels).To(HaveKeyWithValue("app", "guestbook"))
+			Expect(updated.Labels).To(HaveKeyWithValue("external", "value"))
+			Expect(updated.Annotations).To(HaveKeyWithValue("external", "value"))
+		})
+	})
+
+	Context("ServiceMonitor", func() {
+		It("should create and update the object", func() {
+			opts := operatorrules.MonitorOptions{Port: "metrics"}
+			sm := operatorrules.BuildServiceMonitor("guestbook", "default", nil, opts)
+			r := reconciler.New(fakeClient, owner)
+
+			changed, err := r.Reconcile(ctx, sm)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+
+			opts.Path = "/custom-metrics"
+			changed, err = r.Reconcile(ctx, operatorrules.BuildServiceMonitor("guestbook", "default", nil, opts))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+
+			updated := &promv1.ServiceMonitor{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(sm), updated)).To(Succeed())
+			Expect(updated.Spec.Endpoints[0].Path).To(Equal("/custom-metrics"))
+		})
+	})
+
+	Context("RBAC", func() {
+		It("should create the Role and RoleBinding", func() {
+			role, roleBinding := operatorrules.BuildRoleAndRoleBinding("guestbook", "default", "prometheus-k8s", "monitoring", nil)
+
+			changed, err := reconciler.New(fakeClient, owner).Reconcile(ctx, role, roleBinding)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(role), &rbacv1.Role{})).To(Succeed())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(roleBinding), &rbacv1.RoleBinding{})).To(Succeed())
+
+			changed, err = reconciler.New(fakeClient, owner).Reconcile(ctx, role, roleBinding)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeFalse())
+		})
+
+		It("should recreate the RoleBinding when the role reference changes", func() {
+			_, roleBinding := operatorrules.BuildRoleAndRoleBinding("guestbook", "default", "prometheus-k8s", "monitoring", nil)
+			r := reconciler.New(fakeClient, nil)
+
+			_, err := r.ReconcileRoleBinding(ctx, roleBinding)
+			Expect(err).ToNot(HaveOccurred())
+
+			roleBinding.RoleRef.Name = "another-role"
+			changed, err := r.ReconcileRoleBinding(ctx, roleBinding)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+
+			updated := &rbacv1.RoleBinding{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(roleBinding), updated)).To(Succeed())
+			Expect(updated.RoleRef.Name).To(Equal("another-role"))
+		})
+
+		It("should create the cluster scoped RBAC objects", func() {
+			objs := operatorrules.BuildRBAC(operatorrules.RBACOptions{
+				NamePrefix:            "guestbook",
+				ClusterWide:           true,
+				MetricsReader:         true,
+				PrometheusSAName:      "prometheus-k8s",
+				PrometheusSANamespace: "monitoring",
+			})
+
+			changed, err := reconciler.New(fakeClient, nil).ReconcileRBAC(ctx, objs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+
+			clusterRoles := &rbacv1.ClusterRoleList{}
+			Expect(fakeClient.List(ctx, clusterRoles)).To(Succeed())
+			Expect(clusterRoles.Items).To(HaveLen(2))
+
+			clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+			Expect(fakeClient.List(ctx, clusterRoleBindings)).To(Succeed())
+			Expect(clusterRoleBindings.Items).To(HaveLen(2))
+		})
+
+		It("should fail for unsupported object types", func() {
+			_, err := reconciler.New(fakeClient, nil).Reconcile(ctx, &corev1.ConfigMap{})
+			Expect(err).To(MatchError(ContainSubstring("unsupported object type")))
+		})
+	})
+})
@@ -1,6 +1,9 @@
 package testutil_test
 
 import (
+	"os"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -131,5 +134,96 @@ var _ = Describe("Custom Validators", func() {
 			Expect(problems[0].Description).To(ContainSubstring("alert must have a kubernetes_operator_part_of label"))
 			Expect(problems[1].Description).To(ContainSubstring("alert must have a kubernetes_operator_component label"))
 		})
+
+		Context("Runbook URL file", func() {
+			var runbooksDir string
+
+			newAlert := func(runbookURL string) *promv1.Rule {
+				return &promv1.Rule{
+					Alert: "ExampleAlert",
+					Expr:  intstr.FromString("sum(rate(http_requests_total[5m]))"),
+					Labels: map[string]string{
+						"severity": "critical",
+					},
+					Annotations: map[string]string{
+						"summary":     "Example summary",
+						"runbook_url": runbookURL,
+					},
+				}
+			}
+
+			BeforeEach(func() {
+				runbooksDir = GinkgoT().TempDir()
+				Expect(os.WriteFile(filepath.Join(runbooksDir, "ExampleAlert.md"), []byte("# ExampleAlert"), 0o644)).To(Succeed())
+				linter.AddCustomAlertValidations(testutil.ValidateAlertRunbookURLFile(map[string]string{
+					"https://example.com/runbooks/": runbooksDir,
+				}))
+			})
+
+			It("should not return error if the runbook exists", func() {
+				problems := linter.LintAlert(newAlert("https://example.com/runbooks/ExampleAlert"))
+				Expect(problems).To(BeEmpty())
+			})
+
+			It("should not return error if the runbook url has a file extension", func() {
+				problems := linter.LintAlert(newAlert("https://example.com/runbooks/ExampleAlert.md#mitigation"))
+				Expect(problems).To(BeEmpty())
+			})
+
+			It("should resolve the path of the runbook url under the prefix", func() {
+				Expect(os.MkdirAll(filepath.Join(runbooksDir, "guestbook"), 0o755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(runbooksDir, "guestbook", "OtherAlert.md"), []byte("# OtherAlert"), 0o644)).To(Succeed())
+
+				problems := linter.LintAlert(newAlert("https://example.com/runbooks/guestbook/OtherAlert"))
+				Expect(problems).To(BeEmpty())
+
+				problems = linter.LintAlert(newAlert("https://example.com/runbooks/other/ExampleAlert"))
+				Expect(problems).To(HaveLen(1))
+				Expect(problems[0].Description).To(ContainSubstring("has no runbook at " + filepath.Join(runbooksDir, "other", "ExampleAlert.md")))
+			})
+
+			It("should return error if the runbook url matches no prefix", func() {
+				problems := linter.LintAlert(newAlert("https://example.org/runbooks/ExampleAlert"))
+				Expect(problems).To(HaveLen(1))
+				Expect(problems[0].Description).To(ContainSubstring("no runbooks directory for its URL prefix"))
+			})
+
+			It("should match the url prefixes on path segment boundaries", func() {
+				linter = testutil.New()
+				linter.AddCustomAlertValidations(testutil.ValidateAlertRunbookURLFile(map[string]string{
+					"https://example.com/runbooks": runbooksDir,
+				}))
+
+				problems := linter.LintAlert(newAlert("https://example.com/runbooks/ExampleAlert"))
+				Expect(problems).To(BeEmpty())
+
+				problems = linter.LintAlert(newAlert("https://example.com/runbooks-old/ExampleAlert"))
+				Expect(problems).To(HaveLen(1))
+				Expect(problems[0].Description).To(ContainSubstring("no runbooks directory for its URL prefix"))
+			})
+
+			It("should unescape the path of the runbook url", func() {
+				Expect(os.WriteFile(filepath.Join(runbooksDir, "Example Alert.md"), []byte("# Example Alert"), 0o644)).To(Succeed())
+
+				problems := linter.LintAlert(newAlert("https://example.com/runbooks/Example%20Alert"))
+				Expect(problems).To(BeEmpty())
+
+				problems = linter.LintAlert(newAlert("https://example.com/runbooks/%2E%2E%2FExampleAlert"))
+				Expect(problems).To(HaveLen(1))
+				Expect(problems[0].Description).To(ContainSubstring("is outside of the runbooks directory"))
+			})
+
+			It("should return error if the runbook does not exist", func() {
+				problems := linter.LintAlert(newAlert("https://example.com/runbooks/OtherAlert"))
+				Expect(problems).To(HaveLen(1))
+				Expect(problems[0].Description).To(ContainSubstring("has no runbook at " + filepath.Join(runbooksDir, "OtherAlert.md")))
+			})
+
+			It("should return error if the runbook url is missing", func() {
+				problems := linter.LintAlert(newAlert(""))
+				Expect(problems).To(HaveLen(1))
+				Expect(problems[0].Description).To(ContainSubstring("alert must have a runbook_url annotation"))
+			})
+		})
 	})
 })
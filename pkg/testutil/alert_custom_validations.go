@@ -1,6 +1,13 @@
 package testutil
 
 import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
 	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 )
 
@@ -45,6 +52,95 @@ func ValidateAlertRunbookURLAnnotation(alert *promv1.Rule) []Problem {
 	return result
 }
 
+// ValidateAlertRunbookURLFile returns an AlertValidation that checks that the
+// runbook_url annotation of an alert points to an existing runbook in a local
+// runbooks checkout. runbooksDirs maps runbook URL prefixes, such as
+// "https://github.com/org/runbooks/blob/main/", to the local directories they
+// are checked out in, and only match whole path segments. The unescaped path of
+// the URL after the longest matching prefix is looked up in its directory, with
+// a ".md" extension added when it has none.
+func ValidateAlertRunbookURLFile(runbooksDirs map[string]string) AlertValidation {
+	return func(alert *promv1.Rule) []Problem {
+		var result []Problem
+
+		runbookURL := alert.Annotations["runbook_url"]
+		if runbookURL == "" {
+			return append(result, Problem{
+				ResourceName: alert.Alert,
+				Description:  "alert must have a runbook_url annotation",
+			})
+		}
+
+		runbookPath, err := runbookFilePath(runbookURL, runbooksDirs)
+		if err != nil {
+			return append(result, Problem{
+				ResourceName: alert.Alert,
+				Description:  fmt.Sprintf("alert runbook_url %q is not valid: %v", runbookURL, err),
+			})
+		}
+
+		if info, err := os.Stat(runbookPath); err != nil || info.IsDir() {
+			result = append(result, Problem{
+				ResourceName: alert.Alert,
+				Description:  fmt.Sprintf("alert runbook_url %q has no runbook at %s", runbookURL, runbookPath),
+			})
+		}
+
+		return result
+	}
+}
+
+// runbookFilePath returns the local path of the runbook at the given URL,
+// resolved under the directory of the longest URL prefix matching whole path
+// segments, so "https://example.com/runbooks" does not match
+// "https://example.com/runbooks-old/Alert".
+func runbookFilePath(runbookURL string, runbooksDirs map[string]string) (string, error) {
+	u, err := url.Parse(runbookURL)
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	location := u.String()
+
+	var prefix string
+	for p := range runbooksDirs {
+		if hasURLPrefix(location, p) && len(p) > len(prefix) {
+			prefix = p
+		}
+	}
+	if prefix == "" {
+		return "", fmt.Errorf("no runbooks directory for its URL prefix")
+	}
+
+	name, err := url.PathUnescape(strings.TrimPrefix(strings.TrimPrefix(location, prefix), "/"))
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		return "", fmt.Errorf("missing runbook name")
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("runbook path %q is outside of the runbooks directory", name)
+	}
+
+	if path.Ext(name) == "" {
+		name += ".md"
+	}
+
+	return filepath.Join(runbooksDirs[prefix], filepath.FromSlash(name)), nil
+}
+
+// hasURLPrefix reports whether the URL starts with the prefix, ending at a
+// path segment boundary.
+func hasURLPrefix(location, prefix string) bool {
+	if !strings.HasPrefix(location, prefix) {
+		return false
+	}
+	rest := location[len(prefix):]
+	return rest == "" || strings.HasSuffix(prefix, "/") || strings.HasPrefix(rest, "/")
+}
+
 func ValidateAlertHealthImpactLabel(alert *promv1.Rule) []Problem {
 	var result []Problem
 
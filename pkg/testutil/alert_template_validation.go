@@ -0,0 +1,402 @@
+package testutil
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+)
+
+// templateDefs are the variables Prometheus defines before expanding alert
+// annotation templates.
+const templateDefs = "{{$labels := .Labels}}{{$externalLabels := .ExternalLabels}}" +
+	"{{$externalURL := .ExternalURL}}{{$value := .Value}}"
+
+// templateFuncNames are the functions Prometheus adds to the text/template
+// builtins when expanding alert annotation templates.
+var templateFuncNames = []string{
+	"query", "first", "label", "value", "strvalue", "args", "reReplaceAll",
+	"safeHtml", "match", "title", "toUpper", "toLower", "graphLink", "tableLink",
+	"sortByLabel", "humanize", "humanize1024", "humanizeDuration",
+	"humanizePercentage", "humanizeTimestamp", "toTime", "toDuration",
+	"pathPrefix", "externalURL", "parseDuration", "stripPort", "stripDomain", "now",
+}
+
+var templateFuncs = func() template.FuncMap {
+	funcs := template.FuncMap{}
+	for _, name := range templateFuncNames {
+		funcs[name] = func(...interface{}) interface{} { return nil }
+	}
+	return funcs
+}()
+
+// ValidateAlertAnnotationTemplates checks that the alert annotations are valid
+// Prometheus templates, only using known functions and variables.
+func ValidateAlertAnnotationTemplates(alert *promv1.Rule) []Problem {
+	var result []Problem
+
+	for _, key := range sortedAnnotationKeys(alert) {
+		if _, err := parseAnnotationTemplate(key, alert.Annotations[key]); err != nil {
+			result = append(result, Problem{
+				ResourceName: alert.Alert,
+				Description:  fmt.Sprintf("alert annotation %s has an invalid template: %v", key, err),
+			})
+		}
+	}
+
+	return result
+}
+
+// ValidateAlertAnnotationLabels checks that the $labels references of the alert
+// annotations only use labels the alert expression outputs, when they are
+// known from the by or without clause of its top-level aggregation.
+// Annotations that are not valid templates are skipped, as they are reported
+// by ValidateAlertAnnotationTemplates.
+func ValidateAlertAnnotationLabels(alert *promv1.Rule) []Problem {
+	var result []Problem
+
+	outLabels := exprOutputLabels(alert.Expr.String())
+	if outLabels == nil {
+		return result
+	}
+
+	for _, key := range sortedAnnotationKeys(alert) {
+		tpl, err := parseAnnotationTemplate(key, alert.Annotations[key])
+		if err != nil {
+			continue
+		}
+
+		for _, label := range templateLabelRefs(tpl.Tree.Root) {
+			if !outLabels.has(label) {
+				result = append(result, Problem{
+					ResourceName: alert.Alert,
+					Description: fmt.Sprintf("alert annotation %s references label %s, which the alert expression does not output",
+						key, label),
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+func parseAnnotationTemplate(key string, text string) (*template.Template, error) {
+	return template.New(key).Funcs(templateFuncs).Parse(templateDefs + text)
+}
+
+func sortedAnnotationKeys(alert *promv1.Rule) []string {
+	keys := make([]string, 0, len(alert.Annotations))
+	for key := range alert.Annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// templateLabelRefs returns the label names referenced as $labels.name or
+// index $labels "name" in the template tree.
+func templateLabelRefs(node parse.Node) []string {
+	var refs []string
+	seen := map[string]bool{}
+
+	add := func(label string) {
+		if !seen[label] {
+			seen[label] = true
+			refs = append(refs, label)
+		}
+	}
+
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			if label, ok := indexLabelRef(n.Args); ok {
+				add(label)
+			}
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$labels" {
+				add(n.Ident[1])
+			}
+		}
+	}
+	walk(node)
+
+	return refs
+}
+
+func indexLabelRef(args []parse.Node) (string, bool) {
+	if len(args) < 3 {
+		return "", false
+	}
+
+	fn, ok := args[0].(*parse.IdentifierNode)
+	if !ok || fn.Ident != "index" {
+		return "", false
+	}
+
+	v, ok := args[1].(*parse.VariableNode)
+	if !ok || len(v.Ident) != 1 || v.Ident[0] != "$labels" {
+		return "", false
+	}
+
+	s, ok := args[2].(*parse.StringNode)
+	if !ok {
+		return "", false
+	}
+
+	return s.Text, true
+}
+
+// labelSet is the set of labels an expression outputs. When without is set,
+// it outputs all labels except names.
+type labelSet struct {
+	names   map[string]bool
+	without bool
+}
+
+func (s *labelSet) has(label string) bool {
+	return s.names[label] != s.without
+}
+
+var aggregationOperators = map[string]bool{
+	"sum": true, "min": true, "max": true, "avg": true, "group": true,
+	"stddev": true, "stdvar": true, "count": true, "quantile": true,
+}
+
+var binaryOperators = map[string]bool{
+	"+": true, "-": true, "*": true, "/": true, "%": true, "^": true,
+	"==": true, "!=": true, ">": true, "<": true, ">=": true, "<=": true,
+	"and": true, "unless": true, "atan2": true,
+}
+
+// vectorMatchingKeywords change which side of a binary operation the result
+// labels come from, or add labels from the other side.
+var vectorMatchingKeywords = map[string]bool{
+	"or": true, "on": true, "ignoring": true, "group_left": true, "group_right": true,
+}
+
+// exprOutputLabels returns the labels the expression outputs, or nil when they
+// cannot be determined. Only expressions whose result labels come from a
+// top-level aggregation with by or without clauses, optionally compared to
+// scalars or other vectors without vector matching modifiers, are supported.
+func exprOutputLabels(expr string) *labelSet {
+	tokens, ok := tokenizeExpr(expr)
+	if !ok {
+		return nil
+	}
+
+	for _, tok := range tokens {
+		if tok.group == 0 && vectorMatchingKeywords[strings.ToLower(tok.text)] {
+			return nil
+		}
+	}
+
+	var operand []exprToken
+	for i := 0; i <= len(tokens); i++ {
+		if i < len(tokens) {
+			tok := tokens[i]
+			switch word := strings.ToLower(tok.text); {
+			case word == "bool":
+				continue
+			case !binaryOperators[word]:
+				operand = append(operand, tok)
+				continue
+			}
+		}
+
+		if len(operand) == 1 && isNumber(operand[0].text) {
+			operand = nil
+			continue
+		}
+		if len(operand) > 0 {
+			return operandOutputLabels(operand)
+		}
+	}
+
+	return nil
+}
+
+func operandOutputLabels(tokens []exprToken) *labelSet {
+	if len(tokens) == 1 && tokens[0].group == '(' {
+		return exprOutputLabels(tokens[0].text)
+	}
+
+	if len(tokens) < 2 || tokens[0].group != 0 || !aggregationOperators[strings.ToLower(tokens[0].text)] {
+		return nil
+	}
+
+	rest := tokens[1:]
+	switch {
+	case len(rest) == 1 && rest[0].group == '(':
+		return &labelSet{names: map[string]bool{}}
+	case len(rest) == 3 && rest[0].group == 0 && rest[1].group == '(' && rest[2].group == '(':
+		return groupingLabels(rest[0].text, rest[1].text)
+	case len(rest) == 3 && rest[0].group == '(' && rest[1].group == 0 && rest[2].group == '(':
+		return groupingLabels(rest[1].text, rest[2].text)
+	}
+
+	return nil
+}
+
+func groupingLabels(clause string, labels string) *labelSet {
+	clause = strings.ToLower(clause)
+	if clause != "by" && clause != "without" {
+		return nil
+	}
+
+	set := &labelSet{names: map[string]bool{}, without: clause == "without"}
+	for _, label := range strings.Split(labels, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			set.names[label] = true
+		}
+	}
+
+	return set
+}
+
+// exprToken is an identifier, number or operator, or, when group is set, the
+// content of a top-level (), {} or [] group.
+type exprToken struct {
+	text  string
+	group byte
+}
+
+func tokenizeExpr(expr string) ([]exprToken, bool) {
+	var tokens []exprToken
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(expr) && expr[i] != '\n' {
+				i++
+			}
+		case c == '(' || c == '{' || c == '[':
+			end, ok := matchingClose(expr, i)
+			if !ok {
+				return nil, false
+			}
+			tokens = append(tokens, exprToken{text: expr[i+1 : end], group: c})
+			i = end + 1
+		case c == '"' || c == '\'' || c == '`':
+			end, ok := stringEnd(expr, i)
+			if !ok {
+				return nil, false
+			}
+			tokens = append(tokens, exprToken{text: expr[i : end+1]})
+			i = end + 1
+		case isIdentChar(c):
+			start := i
+			for i < len(expr) && isIdentChar(expr[i]) {
+				i++
+			}
+			tokens = append(tokens, exprToken{text: expr[start:i]})
+		case strings.IndexByte("+-*/%^=!<>", c) >= 0:
+			start := i
+			i++
+			if i < len(expr) && expr[i] == '=' && c != '+' && c != '-' {
+				i++
+			}
+			tokens = append(tokens, exprToken{text: expr[start:i]})
+		default:
+			tokens = append(tokens, exprToken{text: string(c)})
+			i++
+		}
+	}
+
+	return tokens, true
+}
+
+var openingBrackets = map[byte]byte{')': '(', '}': '{', ']': '['}
+
+func matchingClose(expr string, open int) (int, bool) {
+	var stack []byte
+
+	for i := open; i < len(expr); i++ {
+		switch c := expr[i]; c {
+		case '(', '{', '[':
+			stack = append(stack, c)
+		case ')', '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != openingBrackets[c] {
+				return 0, false
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i, true
+			}
+		case '"', '\'', '`':
+			end, ok := stringEnd(expr, i)
+			if !ok {
+				return 0, false
+			}
+			i = end
+		}
+	}
+
+	return 0, false
+}
+
+func stringEnd(expr string, start int) (int, bool) {
+	quote := expr[start]
+	for i := start + 1; i < len(expr); i++ {
+		switch expr[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == ':' || c == '.' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
@@ -0,0 +1,109 @@
+package testutil_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("Alert Annotation Template Validation", func() {
+	var linter *testutil.Linter
+
+	BeforeEach(func() {
+		linter = testutil.New()
+		linter.AddCustomAlertValidations(testutil.ValidateAlertAnnotationTemplates, testutil.ValidateAlertAnnotationLabels)
+	})
+
+	newAlert := func(expr string, description string) *promv1.Rule {
+		return &promv1.Rule{
+			Alert: "ExampleAlert",
+			Expr:  intstr.FromString(expr),
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "Example summary",
+				"description": description,
+			},
+		}
+	}
+
+	DescribeTable("should accept valid templates",
+		func(expr string, description string) {
+			problems := linter.LintAlert(newAlert(expr, description))
+			Expect(problems).To(BeEmpty())
+		},
+		Entry("value with humanize",
+			"sum(rate(http_requests_total[5m])) > 10",
+			"Request rate is {{ $value | humanize }}."),
+		Entry("label in by clause",
+			"sum by (namespace) (rate(http_requests_total[5m])) > 10",
+			"Requests in {{ $labels.namespace }} are high."),
+		Entry("label in trailing by clause",
+			"sum(rate(http_requests_total[5m])) BY (namespace, pod) > 10",
+			"Requests in {{ $labels.namespace }}/{{ index $labels \"pod\" }} are high."),
+		Entry("label not in without clause",
+			"sum without (instance) (rate(http_requests_total[5m])) > 10",
+			"Requests in {{ $labels.namespace }} are high."),
+		Entry("scalar compared to aggregation",
+			"10 < sum by (namespace) (rate(http_requests_total[5m]))",
+			"Requests in {{ $labels.namespace }} are high."),
+		Entry("labels of an expression without aggregation",
+			"rate(http_requests_total[5m]) > 10",
+			"Requests in {{ $labels.namespace }} are high."),
+		Entry("labels of an expression with vector matching",
+			"sum by (namespace) (a) > on (namespace) group_left (pod) b",
+			"Requests in {{ $labels.pod }} are high."),
+		Entry("labels in control structures",
+			"sum by (namespace, pod) (up) == 0",
+			"{{ if $labels.pod }}{{ $labels.pod | toUpper }}{{ else }}{{ $labels.namespace }}{{ end }}"),
+	)
+
+	It("should return error for template syntax errors", func() {
+		problems := linter.LintAlert(newAlert("up == 0", "Instance {{ $labels.instance is down."))
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Description).To(ContainSubstring("alert annotation description has an invalid template"))
+	})
+
+	It("should return error for unknown functions", func() {
+		problems := linter.LintAlert(newAlert("up == 0", "Value is {{ $value | humanise }}."))
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Description).To(ContainSubstring(`function "humanise" not defined`))
+	})
+
+	It("should return error for undefined variables", func() {
+		problems := linter.LintAlert(newAlert("up == 0", "Instance {{ $label.instance }} is down."))
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Description).To(ContainSubstring(`undefined variable "$label"`))
+	})
+
+	It("should not check the templates by default", func() {
+		problems := testutil.New().LintAlert(newAlert("sum by (namespace) (up) == 0", "Instance {{ $labels.pod is down."))
+		Expect(problems).To(BeEmpty())
+	})
+
+	DescribeTable("should return error for labels the expression does not output",
+		func(expr string, description string, label string) {
+			problems := linter.LintAlert(newAlert(expr, description))
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].Description).To(Equal(
+				"alert annotation description references label " + label + ", which the alert expression does not output"))
+		},
+		Entry("label not in by clause",
+			"sum by (namespace) (rate(http_requests_total[5m])) > 10",
+			"Requests in {{ $labels.pod }} are high.", "pod"),
+		Entry("label in without clause",
+			"sum without (pod) (rate(http_requests_total[5m])) > 10",
+			"Requests in {{ $labels.pod }} are high.", "pod"),
+		Entry("label of aggregation without clauses",
+			"(count(up == 0)) > 1",
+			"Instance {{ index $labels \"instance\" }} is down.", "instance"),
+		Entry("label in nested template",
+			"sum by (namespace) (up) == 0",
+			"{{ with $labels.namespace }}{{ $labels.pod }}{{ end }}", "pod"),
+	)
+})
@@ -0,0 +1,162 @@
+package testutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CardinalityReport holds the number of series of each metric, sorted from
+// the highest to the lowest.
+type CardinalityReport struct {
+	TotalSeries int
+	Metrics     []MetricCardinality
+}
+
+// MetricCardinality holds the number of series of a metric, and the number
+// of distinct values of each of its labels, sorted from the highest to the
+// lowest.
+type MetricCardinality struct {
+	Name   string
+	Series int
+	Labels []LabelCardinality
+}
+
+// LabelCardinality holds the number of distinct values of a label, and its
+// values with the most series.
+type LabelCardinality struct {
+	Name      string
+	Values    int
+	TopValues []LabelValueCount
+}
+
+// LabelValueCount holds the number of series with a label value.
+type LabelValueCount struct {
+	Value  string
+	Series int
+}
+
+// AnalyzeCardinality returns the cardinality report of the metrics returned
+// by a MetricsFetcher, keeping the topValues label values with the most
+// series.
+func AnalyzeCardinality(metrics map[string][]MetricResult, topValues int) *CardinalityReport {
+	report := &CardinalityReport{}
+
+	// the samples of histograms and summaries are counted with their metric
+	for name, series := range seriesByFamily(metrics) {
+		mc := MetricCardinality{Name: name}
+
+		valueCounts := map[string]map[string]int{}
+		for _, mr := range series {
+			count := seriesCount(mr)
+			mc.Series += count
+
+			for label, value := range mr.Labels {
+				if valueCounts[label] == nil {
+					valueCounts[label] = map[string]int{}
+				}
+				valueCounts[label][value] += count
+			}
+		}
+		report.TotalSeries += mc.Series
+
+		for label, counts := range valueCounts {
+			mc.Labels = append(mc.Labels, LabelCardinality{
+				Name:      label,
+				Values:    len(counts),
+				TopValues: topLabelValues(counts, topValues),
+			})
+		}
+		sort.Slice(mc.Labels, func(i, j int) bool {
+			if mc.Labels[i].Values != mc.Labels[j].Values {
+				return mc.Labels[i].Values > mc.Labels[j].Values
+			}
+			return mc.Labels[i].Name < mc.Labels[j].Name
+		})
+
+		report.Metrics = append(report.Metrics, mc)
+	}
+
+	sort.Slice(report.Metrics, func(i, j int) bool {
+		if report.Metrics[i].Series != report.Metrics[j].Series {
+			return report.Metrics[i].Series > report.Metrics[j].Series
+		}
+		return report.Metrics[i].Name < report.Metrics[j].Name
+	})
+
+	return report
+}
+
+// seriesCount returns the number of series stored by Prometheus for a label
+// set: one per bucket or quantile of histograms and summaries, plus their
+// count and sum.
+func seriesCount(mr MetricResult) int {
+	switch {
+	case mr.Histogram != nil:
+		return len(mr.Histogram.Buckets) + 2
+	case mr.Summary != nil:
+		return len(mr.Summary.Quantiles) + 2
+	default:
+		return 1
+	}
+}
+
+// Metric returns the cardinality of the metric with the given name.
+func (r *CardinalityReport) Metric(name string) (MetricCardinality, bool) {
+	for _, mc := range r.Metrics {
+		if mc.Name == name {
+			return mc, true
+		}
+	}
+	return MetricCardinality{}, false
+}
+
+// Exceeding returns the metrics with more series than the given limit.
+func (r *CardinalityReport) Exceeding(maxSeries int) []MetricCardinality {
+	var exceeding []MetricCardinality
+	for _, mc := range r.Metrics {
+		if mc.Series > maxSeries {
+			exceeding = append(exceeding, mc)
+		}
+	}
+	return exceeding
+}
+
+// String renders the report as a readable table.
+func (r *CardinalityReport) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%d series in %d metrics\n", r.TotalSeries, len(r.Metrics))
+	for _, mc := range r.Metrics {
+		fmt.Fprintf(&sb, "%s: %d series\n", mc.Name, mc.Series)
+		for _, lc := range mc.Labels {
+			values := make([]string, 0, len(lc.TopValues))
+			for _, v := range lc.TopValues {
+				values = append(values, fmt.Sprintf("%q (%d)", v.Value, v.Series))
+			}
+			fmt.Fprintf(&sb, "  %s: %d values, top: %s\n", lc.Name, lc.Values, strings.Join(values, ", "))
+		}
+	}
+
+	return sb.String()
+}
+
+func topLabelValues(counts map[string]int, n int) []LabelValueCount {
+	values := make([]LabelValueCount, 0, len(counts))
+	for value, count := range counts {
+		values = append(values, LabelValueCount{Value: value, Series: count})
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Series != values[j].Series {
+			return values[i].Series > values[j].Series
+		}
+		return values[i].Value < values[j].Value
+	})
+
+	if n >= 0 && len(values) > n {
+		values = values[:n]
+	}
+
+	return values
+}
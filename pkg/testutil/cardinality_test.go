@@ -0,0 +1,87 @@
+package testutil_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("Cardinality", func() {
+	var report *testutil.CardinalityReport
+
+	BeforeEach(func() {
+		metrics, err := testutil.NewMetricsFetcher("").LoadMetrics(metricsEndpointResponse)
+		Expect(err).ToNot(HaveOccurred())
+
+		report = testutil.AnalyzeCardinality(metrics, 1)
+	})
+
+	It("should count the series per metric", func() {
+		Expect(report.TotalSeries).To(Equal(11))
+		Expect(report.Metrics).To(HaveLen(4))
+
+		Expect(report.Metrics[0].Name).To(Equal("kubevirt_migration_count"))
+		Expect(report.Metrics[1].Name).To(Equal("kubevirt_vm_memory_usage_bytes"))
+		Expect(report.Metrics[0].Series).To(Equal(4))
+		Expect(report.Metrics[3].Name).To(Equal("go_info"))
+	})
+
+	It("should count the values per label and keep the top values", func() {
+		mc, ok := report.Metric("kubevirt_migration_count")
+		Expect(ok).To(BeTrue())
+
+		Expect(mc.Labels).To(HaveLen(3))
+		Expect(mc.Labels[0].Name).To(Equal("node"))
+		Expect(mc.Labels[0].Values).To(Equal(2))
+		Expect(mc.Labels[0].TopValues).To(Equal([]testutil.LabelValueCount{{Value: "node01", Series: 2}}))
+		Expect(mc.Labels[2].Name).To(Equal("namespace"))
+		Expect(mc.Labels[2].Values).To(Equal(1))
+	})
+
+	It("should report the metrics exceeding a limit", func() {
+		exceeding := report.Exceeding(3)
+		Expect(exceeding).To(HaveLen(2))
+		Expect(exceeding[0].Name).To(Equal("kubevirt_migration_count"))
+		Expect(exceeding[1].Name).To(Equal("kubevirt_vm_memory_usage_bytes"))
+	})
+
+	It("should count the bucket, quantile, count and sum series of histograms and summaries", func() {
+		metrics, err := testutil.NewMetricsFetcher("").LoadMetrics(
+			"# TYPE operator_latency_seconds histogram\n" +
+				`operator_latency_seconds_bucket{pod="a",le="0.5"} 1` + "\n" +
+				`operator_latency_seconds_bucket{pod="a",le="+Inf"} 2` + "\n" +
+				`operator_latency_seconds_sum{pod="a"} 1.5` + "\n" +
+				`operator_latency_seconds_count{pod="a"} 2` + "\n" +
+				"# TYPE operator_duration_seconds summary\n" +
+				`operator_duration_seconds{quantile="0.5"} 0.2` + "\n" +
+				`operator_duration_seconds{quantile="0.9"} 0.8` + "\n" +
+				`operator_duration_seconds{quantile="0.99"} 0.9` + "\n" +
+				"operator_duration_seconds_sum 5\n" +
+				"operator_duration_seconds_count 10\n",
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		report := testutil.AnalyzeCardinality(metrics, 1)
+		Expect(report.TotalSeries).To(Equal(9))
+
+		latency, ok := report.Metric("operator_latency_seconds")
+		Expect(ok).To(BeTrue())
+		Expect(latency.Series).To(Equal(4))
+		Expect(latency.Labels).To(Equal([]testutil.LabelCardinality{
+			{Name: "pod", Values: 1, TopValues: []testutil.LabelValueCount{{Value: "a", Series: 4}}},
+		}))
+
+		duration, ok := report.Metric("operator_duration_seconds")
+		Expect(ok).To(BeTrue())
+		Expect(duration.Series).To(Equal(5))
+	})
+
+	It("should render a readable report", func() {
+		Expect(report.String()).To(And(
+			HavePrefix("11 series in 4 metrics\n"),
+			ContainSubstring("kubevirt_vm_memory_usage_bytes: 4 series\n"),
+			ContainSubstring(`  vm_name: 4 values, top: "vm1" (1)`),
+		))
+	})
+})
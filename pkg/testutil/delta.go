@@ -0,0 +1,187 @@
+package testutil
+
+import (
+	"sort"
+	"time"
+)
+
+// Snapshot holds the metrics returned by a MetricsFetcher run, and the time
+// they were fetched at.
+type Snapshot struct {
+	Metrics map[string][]MetricResult
+	Time    time.Time
+}
+
+// TakeSnapshot runs the MetricsFetcher and returns its metrics as a Snapshot.
+func TakeSnapshot(fetcher MetricsFetcher) (*Snapshot, error) {
+	metrics, err := fetcher.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{Metrics: metrics, Time: time.Now()}, nil
+}
+
+// SeriesDelta is the change of a series between two snapshots.
+type SeriesDelta struct {
+	Name   string
+	Labels map[string]string
+	Before float64
+	After  float64
+
+	// Delta is the difference between the values. For counters, histograms
+	// and summaries, whose values only increase, a lower value is handled as
+	// a reset and Delta is the value after the reset, as in PromQL increase.
+	Delta float64
+
+	// Rate is Delta per second. The elapsed time is taken from the series
+	// timestamps when both have one, and from the snapshots otherwise.
+	Rate float64
+
+	// Reset is true when the counter was reset between the snapshots.
+	Reset bool
+}
+
+// MetricsDelta is the change of the metrics between two snapshots.
+type MetricsDelta struct {
+	// Series holds the deltas of the series present in both snapshots.
+	Series []SeriesDelta
+
+	// Appeared holds the series only present in the later snapshot.
+	Appeared []MetricResult
+
+	// Disappeared holds the series only present in the earlier snapshot.
+	Disappeared []MetricResult
+
+	// Elapsed is the time between the snapshots.
+	Elapsed time.Duration
+}
+
+// ComputeDelta returns the change of the metrics between the before and after
+// snapshots. Series are identified by their name and labels.
+func ComputeDelta(before, after *Snapshot) *MetricsDelta {
+	delta := &MetricsDelta{Elapsed: after.Time.Sub(before.Time)}
+
+	beforeSeries := indexSeries(before.Metrics)
+	afterSeries := indexSeries(after.Metrics)
+
+	for _, key := range sortedKeys(afterSeries) {
+		a := afterSeries[key]
+
+		b, ok := beforeSeries[key]
+		if !ok {
+			delta.Appeared = append(delta.Appeared, a)
+			continue
+		}
+
+		delta.Series = append(delta.Series, seriesDelta(b, a, delta.Elapsed))
+	}
+
+	for _, key := range sortedKeys(beforeSeries) {
+		if _, ok := afterSeries[key]; !ok {
+			delta.Disappeared = append(delta.Disappeared, beforeSeries[key])
+		}
+	}
+
+	return delta
+}
+
+// Get returns the delta of the series with the given name and exactly the
+// given label key value pairs.
+func (d *MetricsDelta) Get(name string, labelsKeyValue ...string) (SeriesDelta, bool) {
+	key := name + formatLabels(labelPairsToMap(labelsKeyValue))
+
+	for _, sd := range d.Series {
+		if sd.Name+formatLabels(sd.Labels) == key {
+			return sd, true
+		}
+	}
+
+	return SeriesDelta{}, false
+}
+
+// Sum returns the sum of the deltas of the series of a metric with the given
+// label key value pairs. Series that appeared between the snapshots count
+// with their whole value, as if they were previously zero.
+func (d *MetricsDelta) Sum(name string, labelsKeyValue ...string) float64 {
+	labels := labelPairsToMap(labelsKeyValue)
+
+	var sum float64
+	for _, sd := range d.Series {
+		if sd.Name == name && hasLabels(MetricResult{Labels: sd.Labels}, labels) {
+			sum += sd.Delta
+		}
+	}
+	for _, mr := range d.Appeared {
+		if mr.Name == name && hasLabels(mr, labels) {
+			sum += mr.Value
+		}
+	}
+
+	return sum
+}
+
+// Changed returns the deltas of the series whose value changed.
+func (d *MetricsDelta) Changed() []SeriesDelta {
+	var changed []SeriesDelta
+	for _, sd := range d.Series {
+		if sd.Delta != 0 || sd.Reset {
+			changed = append(changed, sd)
+		}
+	}
+	return changed
+}
+
+func seriesDelta(before, after MetricResult, elapsed time.Duration) SeriesDelta {
+	sd := SeriesDelta{
+		Name:   after.Name,
+		Labels: after.Labels,
+		Before: before.Value,
+		After:  after.Value,
+		Delta:  after.Value - before.Value,
+	}
+
+	if isMonotonic(after) && after.Value < before.Value {
+		sd.Reset = true
+		sd.Delta = after.Value
+	}
+
+	if before.Timestamp != nil && after.Timestamp != nil {
+		elapsed = after.Timestamp.Sub(*before.Timestamp)
+	}
+	if elapsed > 0 {
+		sd.Rate = sd.Delta / elapsed.Seconds()
+	}
+
+	return sd
+}
+
+// isMonotonic reports whether the value of the metric only increases until it
+// is reset.
+func isMonotonic(mr MetricResult) bool {
+	switch mr.Type {
+	case "counter", "histogram", "summary":
+		return true
+	default:
+		return false
+	}
+}
+
+func indexSeries(metrics map[string][]MetricResult) map[string]MetricResult {
+	index := make(map[string]MetricResult)
+	for name, series := range metrics {
+		for _, mr := range series {
+			index[name+formatLabels(mr.Labels)] = mr
+		}
+	}
+	return index
+}
+
+func sortedKeys(m map[string]MetricResult) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
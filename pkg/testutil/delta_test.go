@@ -0,0 +1,121 @@
+package testutil_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("Delta", func() {
+	snapshot := func(payload string, at time.Time) *testutil.Snapshot {
+		metrics, err := testutil.NewMetricsFetcher("").LoadMetrics(payload)
+		Expect(err).ToNot(HaveOccurred())
+		return &testutil.Snapshot{Metrics: metrics, Time: at}
+	}
+
+	start := time.Unix(1738861780, 0)
+
+	It("should compute deltas and rates of the series", func() {
+		before := snapshot(
+			"# TYPE operator_reconcile_count counter\n"+
+				`operator_reconcile_count{controller="a"} 10`+"\n"+
+				`operator_reconcile_count{controller="b"} 4`+"\n"+
+				"# TYPE operator_cr_count gauge\n"+
+				"operator_cr_count 5\n",
+			start,
+		)
+		after := snapshot(
+			"# TYPE operator_reconcile_count counter\n"+
+				`operator_reconcile_count{controller="a"} 13`+"\n"+
+				`operator_reconcile_count{controller="b"} 4`+"\n"+
+				"# TYPE operator_cr_count gauge\n"+
+				"operator_cr_count 2\n",
+			start.Add(10*time.Second),
+		)
+
+		delta := testutil.ComputeDelta(before, after)
+		Expect(delta.Elapsed).To(Equal(10 * time.Second))
+
+		sd, ok := delta.Get("operator_reconcile_count", "controller", "a")
+		Expect(ok).To(BeTrue())
+		Expect(sd.Before).To(Equal(10.0))
+		Expect(sd.After).To(Equal(13.0))
+		Expect(sd.Delta).To(Equal(3.0))
+		Expect(sd.Rate).To(BeNumerically("~", 0.3))
+		Expect(sd.Reset).To(BeFalse())
+
+		sd, ok = delta.Get("operator_cr_count")
+		Expect(ok).To(BeTrue())
+		Expect(sd.Delta).To(Equal(-3.0))
+
+		Expect(delta.Sum("operator_reconcile_count")).To(Equal(3.0))
+		Expect(delta.Changed()).To(HaveLen(2))
+		Expect(delta.Appeared).To(BeEmpty())
+		Expect(delta.Disappeared).To(BeEmpty())
+	})
+
+	It("should handle counter resets", func() {
+		before := snapshot("# TYPE operator_reconcile_count counter\noperator_reconcile_count 10\n", start)
+		after := snapshot("# TYPE operator_reconcile_count counter\noperator_reconcile_count 2\n", start.Add(time.Second))
+
+		sd, ok := testutil.ComputeDelta(before, after).Get("operator_reconcile_count")
+		Expect(ok).To(BeTrue())
+		Expect(sd.Reset).To(BeTrue())
+		Expect(sd.Delta).To(Equal(2.0))
+	})
+
+	It("should use the series timestamps for rates", func() {
+		before := snapshot("operator_reconcile_count 10 1738861780000\n", start)
+		after := snapshot("operator_reconcile_count 14 1738861782000\n", start.Add(time.Minute))
+
+		sd, _ := testutil.ComputeDelta(before, after).Get("operator_reconcile_count")
+		Expect(sd.Rate).To(BeNumerically("~", 2))
+	})
+
+	It("should report appeared and disappeared series", func() {
+		before := snapshot(
+			`operator_cr_count{namespace="a"} 1`+"\n"+
+				`operator_cr_count{namespace="b"} 1`+"\n",
+			start,
+		)
+		after := snapshot(
+			`operator_cr_count{namespace="b"} 1`+"\n"+
+				`operator_cr_count{namespace="c"} 2`+"\n",
+			start.Add(time.Second),
+		)
+
+		delta := testutil.ComputeDelta(before, after)
+		Expect(delta.Appeared).To(HaveLen(1))
+		Expect(delta.Appeared[0].Labels).To(HaveKeyWithValue("namespace", "c"))
+		Expect(delta.Disappeared).To(HaveLen(1))
+		Expect(delta.Disappeared[0].Labels).To(HaveKeyWithValue("namespace", "a"))
+		Expect(delta.Changed()).To(BeEmpty())
+		Expect(delta.Sum("operator_cr_count")).To(Equal(2.0))
+	})
+
+	It("should take snapshots from a MetricsFetcher", func() {
+		counter := prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "operator_reconcile_count",
+			Help: "Number of times the operator has executed the reconcile loop",
+		})
+		registry := prometheus.NewRegistry()
+		Expect(registry.Register(counter)).To(Succeed())
+
+		metricsFetcher := testutil.NewGathererMetricsFetcher(registry)
+
+		before, err := testutil.TakeSnapshot(metricsFetcher)
+		Expect(err).ToNot(HaveOccurred())
+
+		counter.Inc()
+
+		after, err := testutil.TakeSnapshot(metricsFetcher)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(testutil.ComputeDelta(before, after).Sum("operator_reconcile_count")).To(Equal(1.0))
+	})
+})
@@ -1,21 +1,69 @@
 package testutil
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
+
+	dto "github.com/prometheus/client_model/go"
 )
 
 // MetricResult represents a single metric.
 type MetricResult struct {
 	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Help      string            `json:"help,omitempty"`
 	Labels    map[string]string `json:"labels"`
 	Value     float64           `json:"value"`
 	Timestamp *time.Time        `json:"timestamp,omitempty"`
+
+	// Histogram holds the buckets, count and sum of the histogram or gauge
+	// histogram series the sample belongs to. It is set on all the samples of
+	// the series, which are returned under their own names, e.g. the _bucket
+	// samples with their le label.
+	Histogram *HistogramResult `json:"histogram,omitempty"`
+
+	// Summary holds the quantiles, count and sum of the summary series the
+	// sample belongs to. It is set on all the samples of the series, which are
+	// returned under their own names, e.g. the quantiles under the metric name
+	// with their quantile label.
+	Summary *SummaryResult `json:"summary,omitempty"`
+
+	// Family is set on the samples of histogram and summary metrics to the
+	// name of the metric they belong to.
+	Family string `json:"family,omitempty"`
+}
+
+// HistogramResult represents the structure of a histogram metric. Buckets
+// always end with the +Inf bucket, as exposed in the text format.
+type HistogramResult struct {
+	SampleCount uint64         `json:"sampleCount"`
+	SampleSum   float64        `json:"sampleSum"`
+	Buckets     []BucketResult `json:"buckets"`
+}
+
+// BucketResult represents a single cumulative bucket of a histogram metric.
+type BucketResult struct {
+	UpperBound      float64 `json:"upperBound"`
+	CumulativeCount uint64  `json:"cumulativeCount"`
+}
+
+// SummaryResult represents the structure of a summary metric.
+type SummaryResult struct {
+	SampleCount uint64           `json:"sampleCount"`
+	SampleSum   float64          `json:"sampleSum"`
+	Quantiles   []QuantileResult `json:"quantiles"`
+}
+
+// QuantileResult represents a single quantile of a summary metric.
+type QuantileResult struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
 }
 
 // MetricsFetcher defines the interface for fetching and loading metrics.
@@ -28,215 +76,339 @@ type MetricsFetcher interface {
 	LoadMetrics(payload string) (map[string][]MetricResult, error)
 }
 
-// DefaultMetricsGetter is the default implementation of MetricsFetcher.
+// FilterableMetricsFetcher is a MetricsFetcher supporting exact and regular
+// expression name filters, label matchers, value predicates and filter sets.
+type FilterableMetricsFetcher interface {
+	MetricsFetcher
+
+	AddExactNameFilter(name string)
+	AddNameRegexFilter(expr string) error
+	AddLabelMatchers(matchers ...*LabelMatcher)
+	AddValueFilter(predicate func(value float64) bool)
+	AddFilterSets(sets ...*FilterSet)
+	ResetFilters()
+}
+
+// DefaultMetricsGetter is the default implementation of
+// FilterableMetricsFetcher.
 type DefaultMetricsGetter struct {
-	URL              string
-	metricNameFilter string
-	labelFilters     map[string]string
-	afterTimestamp   *time.Time
-	beforeTimestamp  *time.Time
-	metrics          map[string][]MetricResult
+	metricsFilter
+
+	URL     string
+	metrics map[string][]MetricResult
+	config  fetchConfig
+
+	// client is built once from the options, so its transport and
+	// connections are reused across runs.
+	client    *http.Client
+	clientErr error
 }
 
-// NewMetricsFetcher creates a new MetricsFetcher instance.
-func NewMetricsFetcher(URL string) MetricsFetcher {
-	return &DefaultMetricsGetter{
-		URL:          URL,
-		labelFilters: make(map[string]string),
-		metrics:      make(map[string][]MetricResult),
+// NewMetricsFetcher creates a new MetricsFetcher instance. The options
+// configure how the URL is queried, e.g. its TLS settings and authentication.
+func NewMetricsFetcher(URL string, opts ...FetchOption) FilterableMetricsFetcher {
+	dmg := &DefaultMetricsGetter{
+		URL:     URL,
+		metrics: make(map[string][]MetricResult),
 	}
-}
 
-func (dmg *DefaultMetricsGetter) AddNameFilter(name string) {
-	dmg.metricNameFilter = name
+	for _, opt := range opts {
+		opt(&dmg.config)
+	}
+
+	dmg.client, dmg.clientErr = dmg.config.httpClient()
+
+	return dmg
 }
 
-func (dmg *DefaultMetricsGetter) AddLabelFilter(labelsKeyValue ...string) {
-	for i := 0; i < len(labelsKeyValue); i += 2 {
-		if i+1 < len(labelsKeyValue) {
-			dmg.labelFilters[labelsKeyValue[i]] = labelsKeyValue[i+1]
+// Run fetches metrics via HTTP, parses them, and applies filters. The
+// protobuf, OpenMetrics and Prometheus text formats are negotiated, in that
+// order of preference.
+func (dmg *DefaultMetricsGetter) Run() (map[string][]MetricResult, error) {
+	if dmg.clientErr != nil {
+		return nil, dmg.clientErr
+	}
+
+	ctx := dmg.config.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = dmg.fetch(ctx, dmg.client)
+
+		var retryable *retryableError
+		if !errors.As(err, &retryable) || attempt >= dmg.config.retries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", err, ctx.Err())
+		case <-time.After(dmg.config.retryInterval):
 		}
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	return dmg.metrics, nil
 }
 
-func (dmg *DefaultMetricsGetter) AddTimestampAfterFilter(ts time.Time) {
-	dmg.afterTimestamp = &ts
+// retryableError wraps errors of requests that may succeed when retried.
+type retryableError struct {
+	err error
 }
 
-func (dmg *DefaultMetricsGetter) AddTimestampBeforeFilter(ts time.Time) {
-	dmg.beforeTimestamp = &ts
+func (e *retryableError) Error() string {
+	return e.err.Error()
 }
 
-// Run fetches metrics via HTTP, parses them, and applies filters.
-func (dmg *DefaultMetricsGetter) Run() (map[string][]MetricResult, error) {
-	resp, err := http.Get(dmg.URL)
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// fetch performs a single request to the metrics endpoint.
+func (dmg *DefaultMetricsGetter) fetch(ctx context.Context, client *http.Client) error {
+	if dmg.config.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dmg.config.timeout)
+		defer cancel()
+	}
+
+	req, err := dmg.config.newRequest(ctx, dmg.URL)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query service endpoint: %w", err)
+		return &retryableError{fmt.Errorf("failed to query service endpoint: %w", err)}
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return &retryableError{fmt.Errorf("unexpected response status: %s", resp.Status)}
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected response status: %s", resp.Status)
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
 	}
 
-	dmg.metrics = make(map[string][]MetricResult)
+	return dmg.processReader(resp.Body, resp.Header.Get("Content-Type"))
+}
 
-	if err := dmg.processReader(resp.Body); err != nil {
+// LoadMetrics parses a provided metrics payload string, in the Prometheus
+// text or OpenMetrics format, and applies filters.
+func (dmg *DefaultMetricsGetter) LoadMetrics(payload string) (map[string][]MetricResult, error) {
+	reader := strings.NewReader(payload)
+	if err := dmg.processReader(reader, ""); err != nil {
 		return nil, err
 	}
 
 	return dmg.metrics, nil
 }
 
-// LoadMetrics parses a provided metrics payload string and applies filters.
-func (dmg *DefaultMetricsGetter) LoadMetrics(payload string) (map[string][]MetricResult, error) {
-	dmg.metrics = make(map[string][]MetricResult)
-
-	reader := strings.NewReader(payload)
-	if err := dmg.processReader(reader); err != nil {
-		return nil, err
+// processReader parses the metric families from the provided io.Reader,
+// applies the filters, and stores the results.
+func (dmg *DefaultMetricsGetter) processReader(r io.Reader, contentType string) error {
+	families, err := parseMetricFamilies(r, contentType)
+	if err != nil {
+		return err
 	}
 
-	return dmg.metrics, nil
+	dmg.metrics = dmg.filterMetricFamilies(families)
+
+	return nil
 }
 
-// processReader reads from the provided io.Reader line by line,
-// parses the metric lines, applies the filters, and stores the results.
-func (dmg *DefaultMetricsGetter) processReader(r io.Reader) error {
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		line := scanner.Text()
-		mr, found, err := dmg.parseLine(line)
-		if err != nil {
-			return err
+// convertMetricFamily returns a MetricResult for each sample of the family, as
+// exposed in the Prometheus text format: histograms have _bucket, _count and
+// _sum samples, and summaries have quantile, _count and _sum samples.
+func convertMetricFamily(mf *dto.MetricFamily) []MetricResult {
+	results := make([]MetricResult, 0, len(mf.GetMetric()))
+
+	for _, m := range mf.GetMetric() {
+		mr := MetricResult{
+			Name:   mf.GetName(),
+			Type:   strings.ToLower(mf.GetType().String()),
+			Help:   mf.GetHelp(),
+			Labels: make(map[string]string, len(m.GetLabel())),
 		}
-		if !found {
-			continue
+
+		for _, l := range m.GetLabel() {
+			mr.Labels[l.GetName()] = l.GetValue()
 		}
-		if dmg.applyFilters(mr) {
-			dmg.metrics[mr.Name] = append(dmg.metrics[mr.Name], *mr)
+
+		if m.TimestampMs != nil {
+			ts := time.UnixMilli(m.GetTimestampMs())
+			mr.Timestamp = &ts
+		}
+
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			mr.Value = m.GetCounter().GetValue()
+		case dto.MetricType_GAUGE:
+			mr.Value = m.GetGauge().GetValue()
+		case dto.MetricType_UNTYPED:
+			mr.Value = m.GetUntyped().GetValue()
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			mr.Histogram = convertHistogram(m.GetHistogram())
+			mr.Family = mr.Name
+			results = append(results, histogramSamples(mr)...)
+			continue
+		case dto.MetricType_SUMMARY:
+			mr.Summary = convertSummary(m.GetSummary())
+			mr.Family = mr.Name
+			results = append(results, summarySamples(mr)...)
+			continue
 		}
-	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("failed to read input: %w", err)
+		results = append(results, mr)
 	}
-	return nil
+
+	return results
 }
 
-// applyFilters checks whether the given MetricResult passes all filters.
-func (dmg *DefaultMetricsGetter) applyFilters(mr *MetricResult) bool {
-	if dmg.metricNameFilter != "" && !strings.HasPrefix(mr.Name, dmg.metricNameFilter) {
-		return false
-	}
-	for k, v := range dmg.labelFilters {
-		if mr.Labels[k] != v {
-			return false
-		}
+// histogramSamples returns the _bucket, _count and _sum samples of a
+// histogram series, or the _bucket, _gcount and _gsum samples of a gauge
+// histogram series.
+func histogramSamples(mr MetricResult) []MetricResult {
+	countSuffix, sumSuffix := "_count", "_sum"
+	if mr.Type == "gauge_histogram" {
+		countSuffix, sumSuffix = "_gcount", "_gsum"
 	}
-	if (dmg.afterTimestamp != nil || dmg.beforeTimestamp != nil) && mr.Timestamp == nil {
-		return false
-	}
-	if dmg.afterTimestamp != nil && mr.Timestamp.Before(*dmg.afterTimestamp) {
-		return false
-	}
-	if dmg.beforeTimestamp != nil && mr.Timestamp.After(*dmg.beforeTimestamp) {
-		return false
+
+	results := make([]MetricResult, 0, len(mr.Histogram.Buckets)+2)
+	for _, b := range mr.Histogram.Buckets {
+		results = append(results, sample(mr, "_bucket", float64(b.CumulativeCount), "le", formatFloat(b.UpperBound)))
 	}
-	return true
+
+	return append(results,
+		sample(mr, sumSuffix, mr.Histogram.SampleSum, "", ""),
+		sample(mr, countSuffix, float64(mr.Histogram.SampleCount), "", ""),
+	)
 }
 
-// parseLine parses a single line of the metrics payload.
-func (dmg *DefaultMetricsGetter) parseLine(line string) (*MetricResult, bool, error) {
-	// Ignore comments and empty lines.
-	if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
-		return nil, false, nil
+// summarySamples returns the quantile, _count and _sum samples of a summary
+// series.
+func summarySamples(mr MetricResult) []MetricResult {
+	results := make([]MetricResult, 0, len(mr.Summary.Quantiles)+2)
+	for _, q := range mr.Summary.Quantiles {
+		results = append(results, sample(mr, "", q.Value, "quantile", formatFloat(q.Quantile)))
 	}
 
-	// Use a custom splitter that respects quotes and curly braces.
-	metaPart, valuePart, err := splitMetricLine(line)
-	if err != nil {
-		return nil, false, err
+	return append(results,
+		sample(mr, "_sum", mr.Summary.SampleSum, "", ""),
+		sample(mr, "_count", float64(mr.Summary.SampleCount), "", ""),
+	)
+}
+
+// sample returns a sample of the series, with the given name suffix, value,
+// and optional extra label such as the le label of histogram buckets.
+func sample(mr MetricResult, suffix string, value float64, extraName, extraValue string) MetricResult {
+	labels := make(map[string]string, len(mr.Labels)+1)
+	for k, v := range mr.Labels {
+		labels[k] = v
+	}
+	if extraName != "" {
+		labels[extraName] = extraValue
 	}
 
-	// Parse the metric name and labels from metaPart.
-	name, labels := dmg.parseMetricNameAndLabels(metaPart)
+	mr.Name += suffix
+	mr.Labels = labels
+	mr.Value = value
 
-	// Now parse the value (and optional timestamp) from the valuePart.
-	parts := strings.Fields(valuePart)
-	if len(parts) < 1 {
-		err = fmt.Errorf("invalid metric line, no value: %s", line)
-		return nil, false, err
-	}
+	return mr
+}
 
-	value, err := strconv.ParseFloat(parts[0], 64)
-	if err != nil {
-		return nil, false, fmt.Errorf("failed to parse metric value: %w", err)
-	}
+// seriesByFamily collapses the samples of histogram and summary metrics into
+// a single MetricResult per series, named after the metric and without the le
+// and quantile labels, with Value holding the sample count. Other metrics are
+// returned as they are. The results are keyed by metric name.
+func seriesByFamily(metrics map[string][]MetricResult) map[string][]MetricResult {
+	series := make(map[string][]MetricResult, len(metrics))
+	seen := make(map[string]map[string]bool)
 
-	var timestamp *time.Time
-	if len(parts) >= 2 {
-		ts, err := strconv.ParseInt(parts[1], 10, 64)
-		if err == nil {
-			t := time.Unix(ts, 0)
-			timestamp = &t
-		}
-	}
+	for name, samples := range metrics {
+		for _, mr := range samples {
+			if mr.Family == "" {
+				series[name] = append(series[name], mr)
+				continue
+			}
 
-	mr := &MetricResult{
-		Name:      name,
-		Labels:    labels,
-		Value:     value,
-		Timestamp: timestamp,
-	}
-	return mr, true, nil
-}
-
-// splitMetricLine splits a metric line into its meta part (name and labels)
-// and its value part. It takes into account quoted strings and curly braces so that
-// spaces inside them are not treated as delimiters.
-func splitMetricLine(line string) (metaPart string, valuePart string, err error) {
-	inQuotes := false
-	inBraces := false
-	for i, r := range line {
-		switch r {
-		case '"':
-			inQuotes = !inQuotes
-		case '{':
-			if !inQuotes {
-				inBraces = true
+			labels := make(map[string]string, len(mr.Labels))
+			for k, v := range mr.Labels {
+				labels[k] = v
 			}
-		case '}':
-			if !inQuotes {
-				inBraces = false
+
+			switch {
+			case mr.Histogram != nil:
+				if mr.Name == mr.Family+"_bucket" {
+					delete(labels, "le")
+				}
+				mr.Value = float64(mr.Histogram.SampleCount)
+			case mr.Summary != nil:
+				if mr.Name == mr.Family {
+					delete(labels, "quantile")
+				}
+				mr.Value = float64(mr.Summary.SampleCount)
+			}
+
+			key := formatLabels(labels)
+			if seen[mr.Family] == nil {
+				seen[mr.Family] = make(map[string]bool)
 			}
-		case ' ':
-			if !inQuotes && !inBraces {
-				metaPart = strings.TrimSpace(line[:i])
-				valuePart = strings.TrimSpace(line[i:])
-				return metaPart, valuePart, nil
+			if seen[mr.Family][key] {
+				continue
 			}
+			seen[mr.Family][key] = true
+
+			mr.Name = mr.Family
+			mr.Labels = labels
+			series[mr.Family] = append(series[mr.Family], mr)
 		}
 	}
-	return "", "", fmt.Errorf("invalid metric line, no value part found: %s", line)
+
+	return series
 }
 
-// parseMetricNameAndLabels splits the metric name from its labels (if any).
-func (dmg *DefaultMetricsGetter) parseMetricNameAndLabels(input string) (string, map[string]string) {
-	labels := make(map[string]string)
-	nameEnd := strings.Index(input, "{")
-	if nameEnd == -1 {
-		return input, labels
+func convertHistogram(h *dto.Histogram) *HistogramResult {
+	result := &HistogramResult{
+		SampleCount: h.GetSampleCount(),
+		SampleSum:   h.GetSampleSum(),
 	}
 
-	name := input[:nameEnd]
-	labelStr := strings.Trim(input[nameEnd:], "{}")
-	labelPairs := strings.Split(labelStr, ",")
-	for _, pair := range labelPairs {
-		kv := strings.SplitN(pair, "=", 2)
-		if len(kv) == 2 {
-			labels[kv[0]] = strings.Trim(kv[1], "\"")
-		}
+	for _, b := range h.GetBucket() {
+		result.Buckets = append(result.Buckets, BucketResult{
+			UpperBound:      b.GetUpperBound(),
+			CumulativeCount: b.GetCumulativeCount(),
+		})
+	}
+
+	// the +Inf bucket is implicit in the protobuf format
+	if n := len(result.Buckets); n == 0 || !math.IsInf(result.Buckets[n-1].UpperBound, 1) {
+		result.Buckets = append(result.Buckets, BucketResult{
+			UpperBound:      math.Inf(1),
+			CumulativeCount: result.SampleCount,
+		})
+	}
+
+	return result
+}
+
+func convertSummary(s *dto.Summary) *SummaryResult {
+	result := &SummaryResult{
+		SampleCount: s.GetSampleCount(),
+		SampleSum:   s.GetSampleSum(),
 	}
-	return name, labels
+
+	for _, q := range s.GetQuantile() {
+		result.Quantiles = append(result.Quantiles, QuantileResult{
+			Quantile: q.GetQuantile(),
+			Value:    q.GetValue(),
+		})
+	}
+
+	return result
 }
@@ -95,7 +95,7 @@ var _ = Describe("FetchMetrics", func() {
 	})
 
 	It("Should return only metrics after a specific timestamp", func() {
-		metricsFetcher.AddTimestampAfterFilter(time.Unix(1738861783, 0))
+		metricsFetcher.AddTimestampAfterFilter(time.UnixMilli(1738861783))
 		metricsFetcher.AddNameFilter("kubevirt_vm_memory_usage_bytes")
 		metrics, err := metricsFetcher.Run()
 		Expect(err).ToNot(HaveOccurred())
@@ -109,7 +109,7 @@ var _ = Describe("FetchMetrics", func() {
 	})
 
 	It("Should return only metrics before a specific timestamp", func() {
-		metricsFetcher.AddTimestampBeforeFilter(time.Unix(1738861783, 0))
+		metricsFetcher.AddTimestampBeforeFilter(time.UnixMilli(1738861783))
 		metricsFetcher.AddNameFilter("kubevirt_vm_memory_usage_bytes")
 		metrics, err := metricsFetcher.Run()
 		Expect(err).ToNot(HaveOccurred())
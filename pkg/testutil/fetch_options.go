@@ -0,0 +1,244 @@
+package testutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// FetchOption configures how a MetricsFetcher queries the metrics endpoint.
+type FetchOption func(*fetchConfig)
+
+type fetchConfig struct {
+	client *http.Client
+
+	caBundle           []byte
+	caFile             string
+	certPEM, keyPEM    []byte
+	certFile, keyFile  string
+	insecureSkipVerify bool
+
+	bearerToken     string
+	bearerTokenFile string
+	headers         http.Header
+
+	ctx     context.Context
+	timeout time.Duration
+
+	retries       int
+	retryInterval time.Duration
+}
+
+// WithHTTPClient sets the HTTP client used to query the metrics endpoint.
+// TLS options are applied on top of a copy of its transport.
+func WithHTTPClient(client *http.Client) FetchOption {
+	return func(c *fetchConfig) {
+		c.client = client
+	}
+}
+
+// WithCABundle sets the PEM encoded CA certificates used to verify the
+// metrics endpoint.
+func WithCABundle(caBundle []byte) FetchOption {
+	return func(c *fetchConfig) {
+		c.caBundle = caBundle
+	}
+}
+
+// WithCAFile sets the file holding the PEM encoded CA certificates used to
+// verify the metrics endpoint. The file is read when the fetcher is created.
+func WithCAFile(path string) FetchOption {
+	return func(c *fetchConfig) {
+		c.caFile = path
+	}
+}
+
+// WithClientCertificate sets the PEM encoded client certificate and key
+// presented to the metrics endpoint.
+func WithClientCertificate(certPEM, keyPEM []byte) FetchOption {
+	return func(c *fetchConfig) {
+		c.certPEM = certPEM
+		c.keyPEM = keyPEM
+	}
+}
+
+// WithClientCertificateFiles sets the files holding the PEM encoded client
+// certificate and key presented to the metrics endpoint. The files are read
+// when the fetcher is created.
+func WithClientCertificateFiles(certFile, keyFile string) FetchOption {
+	return func(c *fetchConfig) {
+		c.certFile = certFile
+		c.keyFile = keyFile
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the metrics endpoint
+// certificate.
+func WithInsecureSkipVerify() FetchOption {
+	return func(c *fetchConfig) {
+		c.insecureSkipVerify = true
+	}
+}
+
+// WithBearerToken sets the bearer token sent in the Authorization header.
+func WithBearerToken(token string) FetchOption {
+	return func(c *fetchConfig) {
+		c.bearerToken = token
+	}
+}
+
+// WithBearerTokenFile sets the file holding the bearer token sent in the
+// Authorization header, such as a service account token. The file is read on
+// every request, so rotated tokens are picked up.
+func WithBearerTokenFile(path string) FetchOption {
+	return func(c *fetchConfig) {
+		c.bearerTokenFile = path
+	}
+}
+
+// WithHeader adds a header to every request.
+func WithHeader(key, value string) FetchOption {
+	return func(c *fetchConfig) {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		c.headers.Add(key, value)
+	}
+}
+
+// WithContext sets the context of every request.
+func WithContext(ctx context.Context) FetchOption {
+	return func(c *fetchConfig) {
+		c.ctx = ctx
+	}
+}
+
+// WithTimeout sets the timeout of each attempt to query the metrics endpoint.
+func WithTimeout(timeout time.Duration) FetchOption {
+	return func(c *fetchConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetries retries failed requests up to the given number of times,
+// waiting interval between attempts. Connection errors and 5xx responses are
+// retried.
+func WithRetries(retries int, interval time.Duration) FetchOption {
+	return func(c *fetchConfig) {
+		c.retries = retries
+		c.retryInterval = interval
+	}
+}
+
+// httpClient returns the client to query the metrics endpoint with. It is
+// built once per fetcher, as cloning the transport on every request would
+// prevent connections from being reused.
+func (c *fetchConfig) httpClient() (*http.Client, error) {
+	client := c.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		return client, nil
+	}
+
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok || transport == nil {
+		if client.Transport != nil {
+			return nil, errors.New("TLS options require the HTTP client to use an *http.Transport")
+		}
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	clientCopy := *client
+	clientCopy.Transport = transport
+
+	return &clientCopy, nil
+}
+
+// tlsConfig returns the TLS configuration built from the options, or nil when
+// no TLS option was set.
+func (c *fetchConfig) tlsConfig() (*tls.Config, error) {
+	if c.caBundle == nil && c.caFile == "" && c.certPEM == nil && c.certFile == "" && !c.insecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.insecureSkipVerify,
+	}
+
+	caBundle := c.caBundle
+	if c.caFile != "" {
+		data, err := os.ReadFile(c.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		caBundle = append(append([]byte{}, caBundle...), data...)
+	}
+	if caBundle != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, errors.New("no valid certificates found in the CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	var cert tls.Certificate
+	var err error
+	switch {
+	case c.certPEM != nil:
+		cert, err = tls.X509KeyPair(c.certPEM, c.keyPEM)
+	case c.certFile != "":
+		cert, err = tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	default:
+		return tlsConfig, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	tlsConfig.Certificates = []tls.Certificate{cert}
+
+	return tlsConfig, nil
+}
+
+// newRequest builds a request to the given URL with the configured context,
+// authentication and headers.
+func (c *fetchConfig) newRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", acceptHeader)
+	for key, values := range c.headers {
+		req.Header[key] = append([]string{}, values...)
+	}
+
+	token := c.bearerToken
+	if c.bearerTokenFile != "" {
+		data, err := os.ReadFile(c.bearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bearer token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,180 @@
+package testutil_test
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("FetchOptions", func() {
+	const payload = "operator_up 1\n"
+
+	var server *ghttp.Server
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("Authentication", func() {
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+		})
+
+		It("should send the bearer token and extra headers", func() {
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyHeaderKV("Authorization", "Bearer my-token"),
+				ghttp.VerifyHeaderKV("X-Tenant", "guestbook"),
+				ghttp.RespondWith(http.StatusOK, payload),
+			))
+
+			metrics, err := testutil.NewMetricsFetcher(server.URL()+"/metrics",
+				testutil.WithBearerToken("my-token"),
+				testutil.WithHeader("X-Tenant", "guestbook"),
+			).Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metrics).To(HaveKey("operator_up"))
+		})
+
+		It("should read the bearer token from a file", func() {
+			tokenFile := filepath.Join(GinkgoT().TempDir(), "token")
+			Expect(os.WriteFile(tokenFile, []byte("file-token\n"), 0o600)).To(Succeed())
+
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyHeaderKV("Authorization", "Bearer file-token"),
+				ghttp.RespondWith(http.StatusOK, payload),
+			))
+
+			_, err := testutil.NewMetricsFetcher(server.URL()+"/metrics",
+				testutil.WithBearerTokenFile(tokenFile),
+			).Run()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should fail when the bearer token file does not exist", func() {
+			_, err := testutil.NewMetricsFetcher(server.URL()+"/metrics",
+				testutil.WithBearerTokenFile(filepath.Join(GinkgoT().TempDir(), "missing")),
+			).Run()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("TLS", func() {
+		BeforeEach(func() {
+			server = ghttp.NewTLSServer()
+			server.AllowUnhandledRequests = true
+			server.UnhandledRequestStatusCode = http.StatusOK
+		})
+
+		It("should fail to verify an unknown certificate authority", func() {
+			_, err := testutil.NewMetricsFetcher(server.URL() + "/metrics").Run()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should verify the server with the CA bundle", func() {
+			caBundle := pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE",
+				Bytes: server.HTTPTestServer.Certificate().Raw,
+			})
+
+			_, err := testutil.NewMetricsFetcher(server.URL()+"/metrics",
+				testutil.WithCABundle(caBundle),
+			).Run()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should skip the verification when asked to", func() {
+			_, err := testutil.NewMetricsFetcher(server.URL()+"/metrics",
+				testutil.WithInsecureSkipVerify(),
+			).Run()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should reuse the connection across runs", func() {
+			var remoteAddrs []string
+			recordRemoteAddr := func(_ http.ResponseWriter, r *http.Request) {
+				remoteAddrs = append(remoteAddrs, r.RemoteAddr)
+			}
+			server.AppendHandlers(
+				ghttp.CombineHandlers(recordRemoteAddr, ghttp.RespondWith(http.StatusOK, payload)),
+				ghttp.CombineHandlers(recordRemoteAddr, ghttp.RespondWith(http.StatusOK, payload)),
+			)
+
+			fetcher := testutil.NewMetricsFetcher(server.URL()+"/metrics", testutil.WithInsecureSkipVerify())
+			for i := 0; i < 2; i++ {
+				_, err := fetcher.Run()
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			Expect(remoteAddrs).To(HaveLen(2))
+			Expect(remoteAddrs[1]).To(Equal(remoteAddrs[0]))
+		})
+
+		It("should fail on an invalid CA bundle", func() {
+			_, err := testutil.NewMetricsFetcher(server.URL()+"/metrics",
+				testutil.WithCABundle([]byte("not a certificate")),
+			).Run()
+			Expect(err).To(MatchError(ContainSubstring("CA bundle")))
+		})
+	})
+
+	Context("Retries and timeouts", func() {
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+		})
+
+		It("should retry on server errors", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusServiceUnavailable, ""),
+				ghttp.RespondWith(http.StatusServiceUnavailable, ""),
+				ghttp.RespondWith(http.StatusOK, payload),
+			)
+
+			metrics, err := testutil.NewMetricsFetcher(server.URL()+"/metrics",
+				testutil.WithRetries(2, time.Millisecond),
+			).Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metrics).To(HaveKey("operator_up"))
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		It("should not retry on client errors", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusForbidden, ""))
+
+			_, err := testutil.NewMetricsFetcher(server.URL()+"/metrics",
+				testutil.WithRetries(2, time.Millisecond),
+			).Run()
+			Expect(err).To(MatchError(ContainSubstring("403")))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("should time out slow requests", func() {
+			server.AppendHandlers(func(http.ResponseWriter, *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			})
+
+			_, err := testutil.NewMetricsFetcher(server.URL()+"/metrics",
+				testutil.WithTimeout(10*time.Millisecond),
+			).Run()
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+		})
+
+		It("should stop on a cancelled context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := testutil.NewMetricsFetcher(server.URL()+"/metrics",
+				testutil.WithContext(ctx),
+			).Run()
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package testutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// GathererMetricsFetcher is a FilterableMetricsFetcher that gathers the metrics
+// directly from a prometheus.Gatherer, such as the registry the operator
+// metrics are registered into, without going through an HTTP endpoint.
+type GathererMetricsFetcher struct {
+	metricsFilter
+
+	gatherer prometheus.Gatherer
+}
+
+// NewGathererMetricsFetcher creates a new MetricsFetcher gathering the
+// metrics from the given prometheus.Gatherer.
+func NewGathererMetricsFetcher(gatherer prometheus.Gatherer) FilterableMetricsFetcher {
+	return &GathererMetricsFetcher{
+		gatherer: gatherer,
+	}
+}
+
+// Run gathers the metrics and applies filters.
+func (gmf *GathererMetricsFetcher) Run() (map[string][]MetricResult, error) {
+	families, err := gmf.gatherer.Gather()
+	if err != nil {
+		return nil, fmt.Errorf("failed to gather metrics: %w", err)
+	}
+
+	return gmf.filterMetricFamilies(families), nil
+}
+
+// LoadMetrics parses a provided metrics payload string, in the Prometheus
+// text or OpenMetrics format, and applies filters.
+func (gmf *GathererMetricsFetcher) LoadMetrics(payload string) (map[string][]MetricResult, error) {
+	families, err := parseMetricFamilies(strings.NewReader(payload), "")
+	if err != nil {
+		return nil, err
+	}
+
+	return gmf.filterMetricFamilies(families), nil
+}
@@ -0,0 +1,130 @@
+package testutil_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("GathererMetricsFetcher", func() {
+	var (
+		registry       *prometheus.Registry
+		metricsFetcher testutil.MetricsFetcher
+
+		crCount = operatormetrics.NewGaugeVec(
+			operatormetrics.MetricOpts{
+				Name: "guestbook_operator_cr_count",
+				Help: "Number of existing guestbook custom resources",
+			},
+			[]string{"namespace"},
+		)
+
+		collectedAt = time.UnixMilli(1738861782000)
+
+		collector = operatormetrics.Collector{
+			Metrics: []operatormetrics.Metric{crCount},
+			CollectCallback: func() []operatormetrics.CollectorResult {
+				return []operatormetrics.CollectorResult{
+					{Metric: crCount, Labels: []string{"default"}, Value: 3},
+					{Metric: crCount, Labels: []string{"guestbook"}, Value: 5, Timestamp: collectedAt},
+				}
+			},
+		}
+
+		reconcileCount = operatormetrics.NewCounter(operatormetrics.MetricOpts{
+			Name: "guestbook_operator_reconcile_count",
+			Help: "Number of times the operator has executed the reconcile loop",
+		})
+	)
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+
+		operatormetrics.Register = registry.Register
+		operatormetrics.Unregister = registry.Unregister
+		DeferCleanup(func() {
+			Expect(operatormetrics.CleanRegistry()).To(Succeed())
+			operatormetrics.Register = prometheus.Register
+			operatormetrics.Unregister = prometheus.Unregister
+		})
+
+		Expect(operatormetrics.RegisterMetrics([]operatormetrics.Metric{reconcileCount})).To(Succeed())
+		Expect(operatormetrics.RegisterCollector(collector)).To(Succeed())
+
+		metricsFetcher = testutil.NewGathererMetricsFetcher(registry)
+	})
+
+	It("should fetch the metrics set by the operator", func() {
+		reconcileCount.Add(2)
+
+		metricsFetcher.AddNameFilter("guestbook_operator_reconcile_count")
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(metrics).To(HaveLen(1))
+		Expect(metrics["guestbook_operator_reconcile_count"]).To(HaveLen(1))
+		Expect(metrics["guestbook_operator_reconcile_count"][0].Type).To(Equal("counter"))
+		Expect(metrics["guestbook_operator_reconcile_count"][0].Value).To(BeNumerically(">=", 2))
+	})
+
+	It("should fetch the metrics produced by collectors", func() {
+		metricsFetcher.AddLabelFilter("namespace", "guestbook")
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+
+		mr := metrics["guestbook_operator_cr_count"]
+		Expect(mr).To(HaveLen(1))
+		Expect(mr[0].Help).To(Equal("Number of existing guestbook custom resources"))
+		Expect(mr[0].Value).To(Equal(5.0))
+		Expect(*mr[0].Timestamp).To(BeTemporally("==", collectedAt))
+	})
+
+	It("should apply the timestamp filters", func() {
+		metricsFetcher.AddTimestampAfterFilter(collectedAt.Add(-time.Second))
+		metrics, err := metricsFetcher.Run()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(metrics).To(HaveLen(1))
+		Expect(metrics["guestbook_operator_cr_count"]).To(HaveLen(1))
+		Expect(metrics["guestbook_operator_cr_count"][0].Labels).To(HaveKeyWithValue("namespace", "guestbook"))
+	})
+
+	It("should return the implicit +Inf bucket of histograms", func() {
+		histogramRegistry := prometheus.NewRegistry()
+		latency := prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "guestbook_operator_latency_seconds",
+			Help:    "Latency of the reconcile loop",
+			Buckets: []float64{0.5},
+		})
+		histogramRegistry.MustRegister(latency)
+		latency.Observe(0.1)
+		latency.Observe(1)
+
+		metrics, err := testutil.NewGathererMetricsFetcher(histogramRegistry).Run()
+		Expect(err).ToNot(HaveOccurred())
+
+		buckets := metrics["guestbook_operator_latency_seconds_bucket"]
+		Expect(buckets).To(HaveLen(2))
+		Expect(buckets[1].Labels).To(HaveKeyWithValue("le", "+Inf"))
+		Expect(buckets[1].Value).To(Equal(2.0))
+		Expect(buckets[1].Histogram.Buckets).To(HaveLen(2))
+		Expect(metrics["guestbook_operator_latency_seconds_count"][0].Value).To(Equal(2.0))
+	})
+
+	It("should return the gatherer errors", func() {
+		metricsFetcher = testutil.NewGathererMetricsFetcher(prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return nil, errors.New("gather failed")
+		}))
+
+		_, err := metricsFetcher.Run()
+		Expect(err).To(MatchError(ContainSubstring("gather failed")))
+	})
+})
@@ -0,0 +1,226 @@
+package testutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// UpdateGoldenEnv is the environment variable that, when set to 1, makes
+// CompareGoldenMetrics write the golden files instead of comparing them.
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// DefaultGoldenIgnoredPrefixes are the prefixes of the metrics left out of
+// golden files by default, as they are exposed by the Go runtime and the
+// process rather than by the operator.
+var DefaultGoldenIgnoredPrefixes = []string{"go_", "process_", "promhttp_"}
+
+// GoldenOptions configures how metrics are normalized for golden files.
+type GoldenOptions struct {
+	// IgnoredPrefixes are the prefixes of the metrics left out of the golden
+	// file. When nil, DefaultGoldenIgnoredPrefixes is used; when empty, no
+	// metric is left out.
+	IgnoredPrefixes []string
+
+	// Update writes the golden file instead of comparing it, as when the
+	// UpdateGoldenEnv environment variable is set to 1.
+	Update bool
+}
+
+// NormalizeMetrics renders the metrics in the Prometheus text format without
+// their volatile parts: timestamps are dropped, the ignored metrics are left
+// out, and metrics and series are sorted.
+func NormalizeMetrics(metrics map[string][]MetricResult, opts GoldenOptions) string {
+	ignoredPrefixes := opts.IgnoredPrefixes
+	if ignoredPrefixes == nil {
+		ignoredPrefixes = DefaultGoldenIgnoredPrefixes
+	}
+
+	// the samples of histograms and summaries are written with their metric
+	seriesByName := seriesByFamily(metrics)
+	names := make([]string, 0, len(seriesByName))
+	for name := range seriesByName {
+		if !hasAnyPrefix(name, ignoredPrefixes) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		series := seriesByName[name]
+		sort.Slice(series, func(i, j int) bool {
+			return formatLabels(series[i].Labels) < formatLabels(series[j].Labels)
+		})
+
+		if help := series[0].Help; help != "" {
+			fmt.Fprintf(&sb, "# HELP %s %s\n", name, helpEscaper.Replace(help))
+		}
+		if typ := series[0].Type; typ != "" {
+			fmt.Fprintf(&sb, "# TYPE %s %s\n", name, typ)
+		}
+		for _, mr := range series {
+			writeSeries(&sb, mr)
+		}
+	}
+
+	return sb.String()
+}
+
+// CompareGoldenMetrics compares the normalized metrics with the golden file,
+// returning an error with a unified diff when they differ. When opts.Update is
+// set or the tests run with UPDATE_GOLDEN=1, the golden file is written
+// instead.
+func CompareGoldenMetrics(metrics map[string][]MetricResult, goldenFile string, opts GoldenOptions) error {
+	actual := NormalizeMetrics(metrics, opts)
+
+	if opts.Update || os.Getenv(UpdateGoldenEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0o755); err != nil {
+			return fmt.Errorf("failed to create golden file directory: %w", err)
+		}
+		if err := os.WriteFile(goldenFile, []byte(actual), 0o644); err != nil {
+			return fmt.Errorf("failed to write golden file: %w", err)
+		}
+		return nil
+	}
+
+	expected, err := os.ReadFile(goldenFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("golden file %s does not exist, run the tests with UPDATE_GOLDEN=1 to create it", goldenFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read golden file: %w", err)
+	}
+
+	if string(expected) == actual {
+		return nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(actual),
+		FromFile: goldenFile,
+		ToFile:   "actual",
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to diff golden file: %w", err)
+	}
+
+	return fmt.Errorf("metrics differ from golden file %s, run the tests with UPDATE_GOLDEN=1 to update it:\n%s", goldenFile, diff)
+}
+
+type goldenMatcher struct {
+	goldenFile string
+	opts       GoldenOptions
+	err        error
+}
+
+// MatchGoldenMetrics succeeds when the actual metrics match the golden file,
+// as compared by CompareGoldenMetrics. The actual value is handled as in
+// HaveMetric.
+func MatchGoldenMetrics(goldenFile string, opts GoldenOptions) types.GomegaMatcher {
+	return &goldenMatcher{goldenFile: goldenFile, opts: opts}
+}
+
+func (m *goldenMatcher) Match(actual interface{}) (bool, error) {
+	metrics, err := metricsFromActual(actual)
+	if err != nil {
+		return false, err
+	}
+
+	m.err = CompareGoldenMetrics(metrics, m.goldenFile, m.opts)
+
+	return m.err == nil, nil
+}
+
+func (m *goldenMatcher) FailureMessage(interface{}) string {
+	return m.err.Error()
+}
+
+func (m *goldenMatcher) NegatedFailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected metrics not to match golden file %s", m.goldenFile)
+}
+
+func writeSeries(sb *strings.Builder, mr MetricResult) {
+	switch {
+	case mr.Histogram != nil:
+		countSuffix, sumSuffix := "_count", "_sum"
+		if mr.Type == "gauge_histogram" {
+			countSuffix, sumSuffix = "_gcount", "_gsum"
+		}
+		for _, b := range mr.Histogram.Buckets {
+			writeSample(sb, mr.Name+"_bucket", mr.Labels, "le", formatFloat(b.UpperBound), float64(b.CumulativeCount))
+		}
+		writeSample(sb, mr.Name+sumSuffix, mr.Labels, "", "", mr.Histogram.SampleSum)
+		writeSample(sb, mr.Name+countSuffix, mr.Labels, "", "", float64(mr.Histogram.SampleCount))
+	case mr.Summary != nil:
+		for _, q := range mr.Summary.Quantiles {
+			writeSample(sb, mr.Name, mr.Labels, "quantile", formatFloat(q.Quantile), q.Value)
+		}
+		writeSample(sb, mr.Name+"_sum", mr.Labels, "", "", mr.Summary.SampleSum)
+		writeSample(sb, mr.Name+"_count", mr.Labels, "", "", float64(mr.Summary.SampleCount))
+	default:
+		writeSample(sb, mr.Name, mr.Labels, "", "", mr.Value)
+	}
+}
+
+// writeSample writes a sample line, with an optional extra label such as
+// the le label of histogram buckets.
+func writeSample(sb *strings.Builder, name string, labels map[string]string, extraName, extraValue string, value float64) {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(labels)+1)
+	for _, k := range names {
+		pairs = append(pairs, k+`="`+labelValueEscaper.Replace(labels[k])+`"`)
+	}
+	if extraName != "" {
+		pairs = append(pairs, extraName+`="`+extraValue+`"`)
+	}
+
+	sb.WriteString(name)
+	if len(pairs) != 0 {
+		sb.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+	sb.WriteString(" " + formatFloat(value) + "\n")
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package testutil_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("Golden", func() {
+	var metrics map[string][]testutil.MetricResult
+
+	BeforeEach(func() {
+		var err error
+		metrics, err = testutil.NewMetricsFetcher("").LoadMetrics(metricsEndpointResponse)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should match the golden file", func() {
+		Expect(metrics).To(testutil.MatchGoldenMetrics("testdata/metrics.golden", testutil.GoldenOptions{}))
+	})
+
+	It("should normalize timestamps, ignored metrics and ordering", func() {
+		normalized := testutil.NormalizeMetrics(metrics, testutil.GoldenOptions{})
+		Expect(normalized).ToNot(ContainSubstring("1738861782000"))
+		Expect(normalized).ToNot(ContainSubstring("go_info"))
+
+		shuffled, err := testutil.NewMetricsFetcher("").LoadMetrics(
+			`kubevirt_vm_count_total{node="node02",namespace="kube-system"} 2` + "\n" +
+				`kubevirt_vm_count_total{namespace="default",node="node01"} 5 1738861782000` + "\n",
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.NormalizeMetrics(shuffled, testutil.GoldenOptions{})).To(Equal(
+			"# TYPE kubevirt_vm_count_total untyped\n" +
+				`kubevirt_vm_count_total{namespace="default",node="node01"} 5` + "\n" +
+				`kubevirt_vm_count_total{namespace="kube-system",node="node02"} 2` + "\n",
+		))
+
+		Expect(testutil.NormalizeMetrics(metrics, testutil.GoldenOptions{IgnoredPrefixes: []string{}})).To(
+			ContainSubstring(`go_info{version="go1.23.4 X:nocoverageredesign"} 1`))
+	})
+
+	It("should render histograms in the text format", func() {
+		histogram, err := testutil.NewMetricsFetcher("").LoadMetrics(
+			"# TYPE operator_latency_seconds histogram\n" +
+				`operator_latency_seconds_bucket{le="0.5"} 1` + "\n" +
+				`operator_latency_seconds_bucket{le="+Inf"} 2` + "\n" +
+				"operator_latency_seconds_sum 1.5\n" +
+				"operator_latency_seconds_count 2\n",
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(testutil.NormalizeMetrics(histogram, testutil.GoldenOptions{})).To(Equal(
+			"# TYPE operator_latency_seconds histogram\n" +
+				`operator_latency_seconds_bucket{le="0.5"} 1` + "\n" +
+				`operator_latency_seconds_bucket{le="+Inf"} 2` + "\n" +
+				"operator_latency_seconds_sum 1.5\n" +
+				"operator_latency_seconds_count 2\n",
+		))
+	})
+
+	It("should render gauge histograms and escape the help text", func() {
+		histogram, err := testutil.NewMetricsFetcher("").LoadMetrics(
+			"# TYPE operator_queue_depth gaugehistogram\n" +
+				`# HELP operator_queue_depth Depth of the queue\nin C:\\queue.` + "\n" +
+				`operator_queue_depth_bucket{le="10"} 3` + "\n" +
+				`operator_queue_depth_bucket{le="+Inf"} 4` + "\n" +
+				"operator_queue_depth_gcount 4\n" +
+				"operator_queue_depth_gsum 25\n" +
+				"# EOF\n",
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(testutil.NormalizeMetrics(histogram, testutil.GoldenOptions{})).To(Equal(
+			`# HELP operator_queue_depth Depth of the queue\nin C:\\queue.` + "\n" +
+				"# TYPE operator_queue_depth gauge_histogram\n" +
+				`operator_queue_depth_bucket{le="10"} 3` + "\n" +
+				`operator_queue_depth_bucket{le="+Inf"} 4` + "\n" +
+				"operator_queue_depth_gsum 25\n" +
+				"operator_queue_depth_gcount 4\n",
+		))
+	})
+
+	It("should report a diff when the metrics change", func() {
+		metrics["kubevirt_vm_count_total"][0].Value = 6
+
+		err := testutil.CompareGoldenMetrics(metrics, "testdata/metrics.golden", testutil.GoldenOptions{})
+		Expect(err).To(MatchError(And(
+			ContainSubstring(`-kubevirt_vm_count_total{namespace="default",node="node01"} 5`),
+			ContainSubstring(`+kubevirt_vm_count_total{namespace="default",node="node01"} 6`),
+			ContainSubstring("UPDATE_GOLDEN=1"),
+		)))
+	})
+
+	It("should fail when the golden file does not exist", func() {
+		goldenFile := filepath.Join(GinkgoT().TempDir(), "missing.golden")
+
+		err := testutil.CompareGoldenMetrics(metrics, goldenFile, testutil.GoldenOptions{})
+		Expect(err).To(MatchError(ContainSubstring("does not exist")))
+	})
+
+	It("should write the golden file with the update environment variable", func() {
+		GinkgoT().Setenv(testutil.UpdateGoldenEnv, "1")
+
+		goldenFile := filepath.Join(GinkgoT().TempDir(), "nested", "metrics.golden")
+		Expect(testutil.CompareGoldenMetrics(metrics, goldenFile, testutil.GoldenOptions{})).To(Succeed())
+
+		data, err := os.ReadFile(goldenFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(testutil.NormalizeMetrics(metrics, testutil.GoldenOptions{})))
+	})
+
+	It("should write the golden file with the update option", func() {
+		goldenFile := filepath.Join(GinkgoT().TempDir(), "metrics.golden")
+		Expect(testutil.CompareGoldenMetrics(metrics, goldenFile, testutil.GoldenOptions{Update: true})).To(Succeed())
+		Expect(testutil.CompareGoldenMetrics(metrics, goldenFile, testutil.GoldenOptions{})).To(Succeed())
+	})
+})
@@ -3,18 +3,21 @@ package testutil
 import (
 	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
 	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
 )
 
 type Linter struct {
 	customAlertValidations      []AlertValidation
 	customRecordRuleValidations []RecordRuleValidation
+	customMetricValidations     []MetricValidation
 }
 
 func New() *Linter {
 	return &Linter{
 		customAlertValidations:      []AlertValidation{},
 		customRecordRuleValidations: []RecordRuleValidation{},
+		customMetricValidations:     []MetricValidation{},
 	}
 }
 
@@ -26,6 +29,10 @@ func (linter *Linter) AddCustomRecordRuleValidations(validations ...RecordRuleVa
 	linter.customRecordRuleValidations = append(linter.customRecordRuleValidations, validations...)
 }
 
+func (linter *Linter) AddCustomMetricValidations(validations ...MetricValidation) {
+	linter.customMetricValidations = append(linter.customMetricValidations, validations...)
+}
+
 func (linter *Linter) LintAlerts(alerts []promv1.Rule) []Problem {
 	var result []Problem
 
@@ -73,3 +80,27 @@ func (linter *Linter) LintRecordingRule(recordingRule *operatorrules.RecordingRu
 
 	return result
 }
+
+func (linter *Linter) LintMetrics(metrics []operatormetrics.Metric) []Problem {
+	var result []Problem
+
+	for _, metric := range metrics {
+		result = append(result, linter.LintMetric(metric)...)
+	}
+
+	return result
+}
+
+func (linter *Linter) LintMetric(metric operatormetrics.Metric) []Problem {
+	var result []Problem
+
+	for _, metricValidation := range defaultMetricValidations {
+		result = append(result, metricValidation(metric)...)
+	}
+
+	for _, metricValidation := range linter.customMetricValidations {
+		result = append(result, metricValidation(metric)...)
+	}
+
+	return result
+}
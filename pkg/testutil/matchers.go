@@ -0,0 +1,277 @@
+package testutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// MetricMatcher is a Gomega matcher succeeding when the actual metrics
+// contain at least one series of a metric matching its labels and value.
+//
+// The actual value can be a map[string][]MetricResult, as returned by
+// MetricsFetcher.Run, a []MetricResult, or a MetricsFetcher, which is run on
+// every match so the matcher can be used with Eventually.
+type MetricMatcher struct {
+	name         string
+	labels       map[string]string
+	valueMatcher types.GomegaMatcher
+
+	series []MetricResult
+}
+
+var _ types.GomegaMatcher = &MetricMatcher{}
+
+// HaveMetric succeeds when the actual metrics contain the metric with the
+// given name.
+func HaveMetric(name string) *MetricMatcher {
+	return &MetricMatcher{name: name}
+}
+
+// WithLabels restricts the matched series to the ones with the given label
+// key value pairs.
+func (m *MetricMatcher) WithLabels(labelsKeyValue ...string) *MetricMatcher {
+	m.labels = labelPairsToMap(labelsKeyValue)
+	return m
+}
+
+// WithValue restricts the matched series to the ones whose value matches.
+// The expected value can be a Gomega matcher or a number.
+func (m *MetricMatcher) WithValue(expected interface{}) *MetricMatcher {
+	m.valueMatcher = numericMatcher("==", expected)
+	return m
+}
+
+// Match implements types.GomegaMatcher.
+func (m *MetricMatcher) Match(actual interface{}) (bool, error) {
+	metrics, err := metricsFromActual(actual)
+	if err != nil {
+		return false, err
+	}
+
+	m.series = metrics[m.name]
+
+	for _, mr := range m.series {
+		if !hasLabels(mr, m.labels) {
+			continue
+		}
+		if m.valueMatcher == nil {
+			return true, nil
+		}
+
+		matches, err := m.valueMatcher.Match(mr.Value)
+		if err != nil {
+			return false, err
+		}
+		if matches {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// FailureMessage implements types.GomegaMatcher.
+func (m *MetricMatcher) FailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected metrics\n%s\nto have a series matching\n%s",
+		format.IndentString(formatSeries(m.series), 1), format.IndentString(m.describe(), 1))
+}
+
+// NegatedFailureMessage implements types.GomegaMatcher.
+func (m *MetricMatcher) NegatedFailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected metrics\n%s\nnot to have a series matching\n%s",
+		format.IndentString(formatSeries(m.series), 1), format.IndentString(m.describe(), 1))
+}
+
+func (m *MetricMatcher) describe() string {
+	description := m.name + formatLabels(m.labels)
+	if m.valueMatcher != nil {
+		description += " with value matching\n" + format.Object(m.valueMatcher, 1)
+	}
+	return description
+}
+
+type metricCountMatcher struct {
+	countMatcher types.GomegaMatcher
+	count        int
+}
+
+// HaveMetricCount succeeds when the number of series in the actual metrics
+// matches the expected count, either a number or a Gomega matcher. The actual
+// value is handled as in HaveMetric.
+func HaveMetricCount(expected interface{}) types.GomegaMatcher {
+	return &metricCountMatcher{countMatcher: numericMatcher("==", expected)}
+}
+
+func (m *metricCountMatcher) Match(actual interface{}) (bool, error) {
+	metrics, err := metricsFromActual(actual)
+	if err != nil {
+		return false, err
+	}
+
+	m.count = 0
+	for _, series := range metrics {
+		m.count += len(series)
+	}
+
+	return m.countMatcher.Match(m.count)
+}
+
+func (m *metricCountMatcher) FailureMessage(interface{}) string {
+	return "Expected the number of metric series to match\n" + m.countMatcher.FailureMessage(m.count)
+}
+
+func (m *metricCountMatcher) NegatedFailureMessage(interface{}) string {
+	return "Expected the number of metric series not to match\n" + m.countMatcher.NegatedFailureMessage(m.count)
+}
+
+// IncreaseMatcher is a Gomega matcher succeeding when the sum of the values
+// of the series of a metric has increased by the expected delta since a
+// baseline, set with Since.
+type IncreaseMatcher struct {
+	deltaMatcher types.GomegaMatcher
+	name         string
+	labels       map[string]string
+
+	baseline *float64
+	delta    float64
+}
+
+var _ types.GomegaMatcher = &IncreaseMatcher{}
+
+// HaveIncreasedBy succeeds when the metric set with ForMetric has increased
+// by the expected delta, either a number or a Gomega matcher, since the
+// baseline set with Since.
+func HaveIncreasedBy(expected interface{}) *IncreaseMatcher {
+	return &IncreaseMatcher{deltaMatcher: numericMatcher("~", expected)}
+}
+
+// ForMetric sets the metric whose increase is matched. Only the series with
+// the given label key value pairs are summed.
+func (m *IncreaseMatcher) ForMetric(name string, labelsKeyValue ...string) *IncreaseMatcher {
+	m.name = name
+	m.labels = labelPairsToMap(labelsKeyValue)
+	return m
+}
+
+// Since sets the metrics the increase is computed from.
+func (m *IncreaseMatcher) Since(baseline map[string][]MetricResult) *IncreaseMatcher {
+	value := m.sum(baseline)
+	m.baseline = &value
+	return m
+}
+
+// Match implements types.GomegaMatcher.
+func (m *IncreaseMatcher) Match(actual interface{}) (bool, error) {
+	if m.name == "" {
+		return false, fmt.Errorf("HaveIncreasedBy requires the metric to be set with ForMetric")
+	}
+	if m.baseline == nil {
+		return false, fmt.Errorf("HaveIncreasedBy requires the baseline to be set with Since")
+	}
+
+	metrics, err := metricsFromActual(actual)
+	if err != nil {
+		return false, err
+	}
+
+	m.delta = m.sum(metrics) - *m.baseline
+	return m.deltaMatcher.Match(m.delta)
+}
+
+// FailureMessage implements types.GomegaMatcher.
+func (m *IncreaseMatcher) FailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected the increase of %s%s to match\n%s",
+		m.name, formatLabels(m.labels), m.deltaMatcher.FailureMessage(m.delta))
+}
+
+// NegatedFailureMessage implements types.GomegaMatcher.
+func (m *IncreaseMatcher) NegatedFailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected the increase of %s%s not to match\n%s",
+		m.name, formatLabels(m.labels), m.deltaMatcher.NegatedFailureMessage(m.delta))
+}
+
+func (m *IncreaseMatcher) sum(metrics map[string][]MetricResult) float64 {
+	var value float64
+	for _, mr := range metrics[m.name] {
+		if hasLabels(mr, m.labels) {
+			value += mr.Value
+		}
+	}
+	return value
+}
+
+// metricsFromActual returns the metrics of the actual value of a matcher.
+func metricsFromActual(actual interface{}) (map[string][]MetricResult, error) {
+	switch a := actual.(type) {
+	case map[string][]MetricResult:
+		return a, nil
+	case []MetricResult:
+		metrics := make(map[string][]MetricResult)
+		for _, mr := range a {
+			metrics[mr.Name] = append(metrics[mr.Name], mr)
+		}
+		return metrics, nil
+	case MetricsFetcher:
+		return a.Run()
+	default:
+		return nil, fmt.Errorf("expected map[string][]MetricResult, []MetricResult or MetricsFetcher, got\n%s", format.Object(actual, 1))
+	}
+}
+
+// numericMatcher returns the expected matcher, or a BeNumerically matcher
+// with the given comparator when a number is expected.
+func numericMatcher(comparator string, expected interface{}) types.GomegaMatcher {
+	if matcher, ok := expected.(types.GomegaMatcher); ok {
+		return matcher
+	}
+	return gomega.BeNumerically(comparator, expected)
+}
+
+func labelPairsToMap(labelsKeyValue []string) map[string]string {
+	labels := make(map[string]string)
+	for i := 0; i+1 < len(labelsKeyValue); i += 2 {
+		labels[labelsKeyValue[i]] = labelsKeyValue[i+1]
+	}
+	return labels
+}
+
+func hasLabels(mr MetricResult, labels map[string]string) bool {
+	for k, v := range labels {
+		if mr.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, v))
+	}
+	sort.Strings(pairs)
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatSeries(series []MetricResult) string {
+	if len(series) == 0 {
+		return "<no series>"
+	}
+
+	lines := make([]string, 0, len(series))
+	for _, mr := range series {
+		lines = append(lines, fmt.Sprintf("%s%s %v", mr.Name, formatLabels(mr.Labels), mr.Value))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,119 @@
+package testutil_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("Matchers", func() {
+	var metrics map[string][]testutil.MetricResult
+
+	BeforeEach(func() {
+		var err error
+		metrics, err = testutil.NewMetricsFetcher("").LoadMetrics(metricsEndpointResponse)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("HaveMetric", func() {
+		It("should match metrics by name, labels and value", func() {
+			Expect(metrics).To(testutil.HaveMetric("kubevirt_vm_count_total"))
+			Expect(metrics).To(testutil.HaveMetric("kubevirt_migration_count").
+				WithLabels("node", "node02", "status", "failed").
+				WithValue(1))
+			Expect(metrics).To(testutil.HaveMetric("kubevirt_vm_count_total").
+				WithLabels("namespace", "kube-system").
+				WithValue(BeNumerically(">", 0)))
+		})
+
+		It("should not match missing series", func() {
+			Expect(metrics).ToNot(testutil.HaveMetric("non_existent_metric"))
+			Expect(metrics).ToNot(testutil.HaveMetric("kubevirt_migration_count").WithLabels("node", "node03"))
+			Expect(metrics).ToNot(testutil.HaveMetric("kubevirt_migration_count").
+				WithLabels("status", "failed").
+				WithValue(BeNumerically(">", 2)))
+		})
+
+		It("should describe the found series on failure", func() {
+			matcher := testutil.HaveMetric("kubevirt_vm_count_total").WithLabels("node", "node03")
+			Expect(matcher.Match(metrics)).To(BeFalse())
+			Expect(matcher.FailureMessage(metrics)).To(And(
+				ContainSubstring(`kubevirt_vm_count_total{namespace="default",node="node01"} 5`),
+				ContainSubstring(`kubevirt_vm_count_total{node="node03"}`),
+			))
+		})
+
+		It("should fail on unsupported actual values", func() {
+			_, err := testutil.HaveMetric("kubevirt_vm_count_total").Match("kubevirt_vm_count_total")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("HaveMetricCount", func() {
+		It("should match the number of series", func() {
+			Expect(metrics).To(testutil.HaveMetricCount(11))
+			Expect(metrics["kubevirt_migration_count"]).To(testutil.HaveMetricCount(BeNumerically(">=", 4)))
+		})
+	})
+
+	Context("HaveIncreasedBy", func() {
+		var (
+			counter        prometheus.Counter
+			metricsFetcher testutil.MetricsFetcher
+		)
+
+		BeforeEach(func() {
+			counter = prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "guestbook_operator_reconcile_count",
+				Help: "Number of times the operator has executed the reconcile loop",
+			})
+
+			registry := prometheus.NewRegistry()
+			Expect(registry.Register(counter)).To(Succeed())
+
+			metricsFetcher = testutil.NewGathererMetricsFetcher(registry)
+		})
+
+		It("should match the increase since a baseline", func() {
+			baseline, err := metricsFetcher.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			counter.Add(3)
+
+			Expect(metricsFetcher).To(testutil.HaveIncreasedBy(3).
+				ForMetric("guestbook_operator_reconcile_count").
+				Since(baseline))
+		})
+
+		It("should poll until the metric has increased", func() {
+			baseline, err := metricsFetcher.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				defer GinkgoRecover()
+				time.Sleep(50 * time.Millisecond)
+				counter.Add(2)
+			}()
+
+			Eventually(metricsFetcher).
+				WithTimeout(time.Second).
+				WithPolling(10 * time.Millisecond).
+				Should(testutil.HaveIncreasedBy(2).ForMetric("guestbook_operator_reconcile_count").Since(baseline))
+		})
+
+		It("should require the baseline to be set", func() {
+			_, err := testutil.HaveIncreasedBy(1).ForMetric("guestbook_operator_reconcile_count").Match(metricsFetcher)
+			Expect(err).To(MatchError(ContainSubstring("Since")))
+		})
+
+		It("should require the metric to be set", func() {
+			_, err := testutil.HaveIncreasedBy(1).Match(metrics)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
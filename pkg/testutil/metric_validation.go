@@ -0,0 +1,85 @@
+package testutil
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+)
+
+type MetricValidation = func(metric operatormetrics.Metric) []Problem
+
+var defaultMetricValidations = []MetricValidation{
+	validateMetricAllowedLabelValues,
+}
+
+func validateMetricAllowedLabelValues(metric operatormetrics.Metric) []Problem {
+	var result []Problem
+
+	opts := metric.GetOpts()
+
+	labels := make([]string, 0, len(opts.AllowedLabelValues))
+	for label := range opts.AllowedLabelValues {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		values := opts.AllowedLabelValues[label]
+
+		if !slices.Contains(opts.Labels(), label) {
+			result = append(result, Problem{
+				ResourceName: opts.Name,
+				Description:  fmt.Sprintf("allowed values are declared for label %s, which is not a label of the metric", label),
+			})
+		}
+
+		if len(values) == 0 {
+			result = append(result, Problem{
+				ResourceName: opts.Name,
+				Description:  fmt.Sprintf("label %s must have at least one allowed value", label),
+			})
+		}
+
+		seen := map[string]struct{}{}
+		for _, value := range values {
+			if _, ok := seen[value]; ok {
+				result = append(result, Problem{
+					ResourceName: opts.Name,
+					Description:  fmt.Sprintf("label %s has duplicate allowed value %q", label, value),
+				})
+			}
+			seen[value] = struct{}{}
+		}
+
+		if opts.LabelValuePolicy == operatormetrics.LabelValueCoerce && slices.Contains(values, operatormetrics.OtherLabelValue) {
+			result = append(result, Problem{
+				ResourceName: opts.Name,
+				Description: fmt.Sprintf("label %s allows value %q, which is indistinguishable from coerced values",
+					label, operatormetrics.OtherLabelValue),
+			})
+		}
+	}
+
+	return result
+}
+
+// ValidateMetricNamePrefix returns a validation checking that the metric name
+// starts with the operator prefix, such as the OptsBuilder name prefix.
+func ValidateMetricNamePrefix(prefix string) MetricValidation {
+	return func(metric operatormetrics.Metric) []Problem {
+		var result []Problem
+
+		name := metric.GetOpts().Name
+		if !strings.HasPrefix(name, prefix) {
+			result = append(result, Problem{
+				ResourceName: name,
+				Description:  fmt.Sprintf("metric name must start with the prefix %s", prefix),
+			})
+		}
+
+		return result
+	}
+}
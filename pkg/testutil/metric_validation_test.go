@@ -0,0 +1,95 @@
+package testutil_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/machadovilaca/operator-observability/pkg/operatormetrics"
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("Metric Validators", func() {
+	var linter *testutil.Linter
+
+	BeforeEach(func() {
+		linter = testutil.New()
+	})
+
+	Context("Allowed label values", func() {
+		It("should validate metric with valid allowed label values", func() {
+			metric := operatormetrics.NewCounterVec(operatormetrics.MetricOpts{
+				Name:               "example_reconcile_action_count",
+				Help:               "Number of reconcile actions",
+				AllowedLabelValues: map[string][]string{"action": {"create", "update", "delete"}},
+			}, []string{"action"})
+
+			Expect(linter.LintMetric(metric)).To(BeEmpty())
+		})
+
+		It("should return error if allowed values are declared for an unknown label", func() {
+			metric := operatormetrics.NewGaugeVec(operatormetrics.MetricOpts{
+				Name:               "example_phase",
+				Help:               "Phase of the resource",
+				AllowedLabelValues: map[string][]string{"phases": {"Running"}},
+			}, []string{"phase"})
+
+			problems := linter.LintMetric(metric)
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].ResourceName).To(Equal("example_phase"))
+			Expect(problems[0].Description).To(ContainSubstring("label phases, which is not a label of the metric"))
+		})
+
+		It("should return error if allowed values are empty or duplicated", func() {
+			metric := operatormetrics.NewGaugeVec(operatormetrics.MetricOpts{
+				Name: "example_phase",
+				Help: "Phase of the resource",
+				AllowedLabelValues: map[string][]string{
+					"phase":  {"Running", "Running"},
+					"reason": {},
+				},
+			}, []string{"phase", "reason"})
+
+			problems := linter.LintMetrics([]operatormetrics.Metric{metric})
+			Expect(problems).To(HaveLen(2))
+			Expect(problems[0].Description).To(ContainSubstring(`label phase has duplicate allowed value "Running"`))
+			Expect(problems[1].Description).To(ContainSubstring("label reason must have at least one allowed value"))
+		})
+
+		It("should return error if the coerced value is an allowed value", func() {
+			metric := operatormetrics.NewCounterVec(operatormetrics.MetricOpts{
+				Name:               "example_reconcile_action_count",
+				Help:               "Number of reconcile actions",
+				AllowedLabelValues: map[string][]string{"action": {"create", operatormetrics.OtherLabelValue}},
+				LabelValuePolicy:   operatormetrics.LabelValueCoerce,
+			}, []string{"action"})
+
+			problems := linter.LintMetric(metric)
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].Description).To(ContainSubstring("indistinguishable from coerced values"))
+		})
+	})
+
+	Context("Name prefix", func() {
+		It("should return error if the metric name does not start with the prefix", func() {
+			linter.AddCustomMetricValidations(testutil.ValidateMetricNamePrefix("guestbook_operator_"))
+
+			problems := linter.LintMetrics([]operatormetrics.Metric{
+				operatormetrics.NewGauge(operatormetrics.MetricOpts{Name: "guestbook_operator_up"}),
+				operatormetrics.NewGauge(operatormetrics.MetricOpts{Name: "reconcile_count"}),
+			})
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].ResourceName).To(Equal("reconcile_count"))
+			Expect(problems[0].Description).To(Equal("metric name must start with the prefix guestbook_operator_"))
+		})
+	})
+
+	It("should run custom metric validations", func() {
+		linter.AddCustomMetricValidations(func(metric operatormetrics.Metric) []testutil.Problem {
+			return []testutil.Problem{{ResourceName: metric.GetOpts().Name, Description: "custom problem"}}
+		})
+
+		problems := linter.LintMetric(operatormetrics.NewGauge(operatormetrics.MetricOpts{Name: "example_gauge"}))
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Description).To(Equal("custom problem"))
+	})
+})
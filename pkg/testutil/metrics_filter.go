@@ -0,0 +1,249 @@
+package testutil
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/regexp"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// MatchType is the type of a LabelMatcher, following the PromQL label
+// matching operators.
+type MatchType string
+
+const (
+	// MatchEqual matches label values equal to the matcher value.
+	MatchEqual MatchType = "="
+	// MatchNotEqual matches label values different from the matcher value.
+	MatchNotEqual MatchType = "!="
+	// MatchRegexp matches label values fully matching the matcher regex.
+	MatchRegexp MatchType = "=~"
+	// MatchNotRegexp matches label values not fully matching the matcher regex.
+	MatchNotRegexp MatchType = "!~"
+)
+
+// LabelMatcher matches the value of a label. As in PromQL, a missing label
+// has an empty value.
+type LabelMatcher struct {
+	Name  string
+	Type  MatchType
+	Value string
+
+	re *regexp.Regexp
+}
+
+// NewLabelMatcher returns a LabelMatcher, or an error if the match type is
+// unknown or the regex is invalid.
+func NewLabelMatcher(name string, matchType MatchType, value string) (*LabelMatcher, error) {
+	m := &LabelMatcher{Name: name, Type: matchType, Value: value}
+
+	switch matchType {
+	case MatchEqual, MatchNotEqual:
+	case MatchRegexp, MatchNotRegexp:
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex for label %s: %w", name, err)
+		}
+		m.re = re
+	default:
+		return nil, fmt.Errorf("unknown match type %q for label %s", matchType, name)
+	}
+
+	return m, nil
+}
+
+// MustNewLabelMatcher is like NewLabelMatcher but panics on error.
+func MustNewLabelMatcher(name string, matchType MatchType, value string) *LabelMatcher {
+	m, err := NewLabelMatcher(name, matchType, value)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Matches reports whether the label value matches.
+func (m *LabelMatcher) Matches(value string) bool {
+	switch m.Type {
+	case MatchEqual:
+		return value == m.Value
+	case MatchNotEqual:
+		return value != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(value)
+	case MatchNotRegexp:
+		return !m.re.MatchString(value)
+	default:
+		return false
+	}
+}
+
+func (m *LabelMatcher) String() string {
+	return fmt.Sprintf("%s%s%q", m.Name, m.Type, m.Value)
+}
+
+// FilterSet is a set of filters a metric must all pass. The zero value is an
+// empty set letting all metrics through.
+type FilterSet struct {
+	nameMatcher     func(string) bool
+	labelFilters    map[string]string
+	labelMatchers   []*LabelMatcher
+	valueFilters    []func(float64) bool
+	afterTimestamp  *time.Time
+	beforeTimestamp *time.Time
+}
+
+// AddNameFilter keeps the metrics whose name starts with the given prefix. It
+// replaces any previous name filter.
+func (f *FilterSet) AddNameFilter(name string) {
+	f.nameMatcher = func(s string) bool {
+		return strings.HasPrefix(s, name)
+	}
+}
+
+// AddExactNameFilter keeps the metrics with the given name. It replaces any
+// previous name filter.
+func (f *FilterSet) AddExactNameFilter(name string) {
+	f.nameMatcher = func(s string) bool {
+		return s == name
+	}
+}
+
+// AddNameRegexFilter keeps the metrics whose name fully matches the given
+// regex. It replaces any previous name filter.
+func (f *FilterSet) AddNameRegexFilter(expr string) error {
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid metric name regex: %w", err)
+	}
+
+	f.nameMatcher = re.MatchString
+
+	return nil
+}
+
+// AddLabelFilter keeps the metrics with the given label key value pairs.
+func (f *FilterSet) AddLabelFilter(labelsKeyValue ...string) {
+	if f.labelFilters == nil {
+		f.labelFilters = make(map[string]string)
+	}
+
+	for i := 0; i < len(labelsKeyValue); i += 2 {
+		if i+1 < len(labelsKeyValue) {
+			f.labelFilters[labelsKeyValue[i]] = labelsKeyValue[i+1]
+		}
+	}
+}
+
+// AddLabelMatchers keeps the metrics whose labels match all the given
+// matchers.
+func (f *FilterSet) AddLabelMatchers(matchers ...*LabelMatcher) {
+	f.labelMatchers = append(f.labelMatchers, matchers...)
+}
+
+// AddValueFilter keeps the metrics whose value satisfies the predicate.
+func (f *FilterSet) AddValueFilter(predicate func(value float64) bool) {
+	f.valueFilters = append(f.valueFilters, predicate)
+}
+
+// AddTimestampAfterFilter keeps the metrics with a timestamp not before ts.
+func (f *FilterSet) AddTimestampAfterFilter(ts time.Time) {
+	f.afterTimestamp = &ts
+}
+
+// AddTimestampBeforeFilter keeps the metrics with a timestamp not after ts.
+func (f *FilterSet) AddTimestampBeforeFilter(ts time.Time) {
+	f.beforeTimestamp = &ts
+}
+
+// Reset removes all the filters of the set.
+func (f *FilterSet) Reset() {
+	*f = FilterSet{}
+}
+
+// matches checks whether the given MetricResult passes all filters.
+func (f *FilterSet) matches(mr *MetricResult) bool {
+	if f.nameMatcher != nil && !f.nameMatcher(mr.Name) {
+		return false
+	}
+	for k, v := range f.labelFilters {
+		if mr.Labels[k] != v {
+			return false
+		}
+	}
+	for _, m := range f.labelMatchers {
+		if !m.Matches(mr.Labels[m.Name]) {
+			return false
+		}
+	}
+	for _, predicate := range f.valueFilters {
+		if !predicate(mr.Value) {
+			return false
+		}
+	}
+	if (f.afterTimestamp != nil || f.beforeTimestamp != nil) && mr.Timestamp == nil {
+		return false
+	}
+	if f.afterTimestamp != nil && mr.Timestamp.Before(*f.afterTimestamp) {
+		return false
+	}
+	if f.beforeTimestamp != nil && mr.Timestamp.After(*f.beforeTimestamp) {
+		return false
+	}
+	return true
+}
+
+// metricsFilter holds the filters shared by the MetricsFetcher
+// implementations: the filters added to the fetcher, which a metric must all
+// pass, and the added filter sets, of which a metric must pass at least one.
+type metricsFilter struct {
+	FilterSet
+
+	filterSets []*FilterSet
+}
+
+// AddFilterSets keeps the metrics passing at least one of the filter sets, on
+// top of the other filters.
+func (f *metricsFilter) AddFilterSets(sets ...*FilterSet) {
+	f.filterSets = append(f.filterSets, sets...)
+}
+
+// ResetFilters removes all the filters and filter sets, so the fetcher can be
+// reused with different filters.
+func (f *metricsFilter) ResetFilters() {
+	f.FilterSet.Reset()
+	f.filterSets = nil
+}
+
+// filterMetricFamilies converts the metric families and returns the results
+// passing the filters, keyed by metric name.
+func (f *metricsFilter) filterMetricFamilies(families []*dto.MetricFamily) map[string][]MetricResult {
+	metrics := make(map[string][]MetricResult)
+
+	for _, mf := range families {
+		for _, mr := range convertMetricFamily(mf) {
+			if f.applyFilters(&mr) {
+				metrics[mr.Name] = append(metrics[mr.Name], mr)
+			}
+		}
+	}
+
+	return metrics
+}
+
+// applyFilters checks whether the given MetricResult passes the filters.
+func (f *metricsFilter) applyFilters(mr *MetricResult) bool {
+	if !f.FilterSet.matches(mr) {
+		return false
+	}
+	if len(f.filterSets) == 0 {
+		return true
+	}
+	for _, set := range f.filterSets {
+		if set.matches(mr) {
+			return true
+		}
+	}
+	return false
+}
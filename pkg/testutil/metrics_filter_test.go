@@ -0,0 +1,110 @@
+package testutil_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("MetricsFilter", func() {
+	var metricsFetcher testutil.FilterableMetricsFetcher
+
+	load := func() map[string][]testutil.MetricResult {
+		metrics, err := metricsFetcher.LoadMetrics(metricsEndpointResponse)
+		Expect(err).ToNot(HaveOccurred())
+		return metrics
+	}
+
+	BeforeEach(func() {
+		metricsFetcher = testutil.NewMetricsFetcher("")
+	})
+
+	Context("LabelMatcher", func() {
+		DescribeTable("should match label values",
+			func(matchType testutil.MatchType, value, labelValue string, expected bool) {
+				m, err := testutil.NewLabelMatcher("phase", matchType, value)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(m.Matches(labelValue)).To(Equal(expected))
+			},
+			Entry("equal", testutil.MatchEqual, "Running", "Running", true),
+			Entry("equal mismatch", testutil.MatchEqual, "Running", "Pending", false),
+			Entry("not equal", testutil.MatchNotEqual, "Running", "Pending", true),
+			Entry("regex", testutil.MatchRegexp, "Running|Pending", "Pending", true),
+			Entry("regex is anchored", testutil.MatchRegexp, "Run", "Running", false),
+			Entry("not regex", testutil.MatchNotRegexp, "Running|Pending", "Failed", true),
+			Entry("not regex mismatch", testutil.MatchNotRegexp, "Running|Pending", "Running", false),
+		)
+
+		It("should fail on invalid matchers", func() {
+			_, err := testutil.NewLabelMatcher("phase", testutil.MatchRegexp, "(")
+			Expect(err).To(HaveOccurred())
+
+			_, err = testutil.NewLabelMatcher("phase", "==", "Running")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("should filter by label matchers", func() {
+		metricsFetcher.AddLabelMatchers(
+			testutil.MustNewLabelMatcher("namespace", testutil.MatchNotEqual, "kube-system"),
+			testutil.MustNewLabelMatcher("node", testutil.MatchRegexp, "node0[12]"),
+			testutil.MustNewLabelMatcher("status", testutil.MatchNotRegexp, "failed"),
+		)
+
+		metrics := load()
+		Expect(metrics).To(HaveLen(2))
+		Expect(metrics["kubevirt_vm_count_total"]).To(HaveLen(1))
+		Expect(metrics["kubevirt_migration_count"]).To(HaveLen(2))
+	})
+
+	It("should filter by exact and regex names", func() {
+		metricsFetcher.AddExactNameFilter("kubevirt_vm")
+		Expect(load()).To(BeEmpty())
+
+		Expect(metricsFetcher.AddNameRegexFilter("kubevirt_(vm_count|migration)_.*")).To(Succeed())
+		metrics := load()
+		Expect(metrics).To(HaveLen(2))
+		Expect(metrics).To(HaveKey("kubevirt_vm_count_total"))
+		Expect(metrics).To(HaveKey("kubevirt_migration_count"))
+
+		Expect(metricsFetcher.AddNameRegexFilter("(")).ToNot(Succeed())
+	})
+
+	It("should filter by value", func() {
+		metricsFetcher.AddNameFilter("kubevirt_migration_count")
+		metricsFetcher.AddValueFilter(func(value float64) bool { return value > 2 })
+
+		metrics := load()
+		Expect(metrics["kubevirt_migration_count"]).To(HaveLen(2))
+		for _, mr := range metrics["kubevirt_migration_count"] {
+			Expect(mr.Labels).To(HaveKeyWithValue("status", "succeeded"))
+		}
+	})
+
+	It("should keep the metrics passing any of the filter sets", func() {
+		failed := &testutil.FilterSet{}
+		failed.AddLabelFilter("status", "failed")
+
+		vm3 := &testutil.FilterSet{}
+		vm3.AddLabelFilter("vm_name", "vm3")
+
+		metricsFetcher.AddNameFilter("kubevirt_")
+		metricsFetcher.AddFilterSets(failed, vm3)
+
+		metrics := load()
+		Expect(metrics).To(HaveLen(2))
+		Expect(metrics["kubevirt_migration_count"]).To(HaveLen(2))
+		Expect(metrics["kubevirt_vm_memory_usage_bytes"]).To(HaveLen(1))
+	})
+
+	It("should reset the filters between runs", func() {
+		metricsFetcher.AddExactNameFilter("kubevirt_vm_count_total")
+		metricsFetcher.AddLabelFilter("node", "node02")
+		metricsFetcher.AddFilterSets(&testutil.FilterSet{})
+		Expect(load()).To(testutil.HaveMetricCount(1))
+
+		metricsFetcher.ResetFilters()
+		Expect(load()).To(testutil.HaveMetricCount(11))
+	})
+})
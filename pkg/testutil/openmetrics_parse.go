@@ -0,0 +1,480 @@
+package testutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+// openMetricsFamily holds a metric family while its samples are parsed.
+type openMetricsFamily struct {
+	name   string
+	typ    string
+	typed  bool
+	family *dto.MetricFamily
+	series map[string]*dto.Metric
+}
+
+type openMetricsSample struct {
+	name        string
+	labels      []*dto.LabelPair
+	value       float64
+	timestampMs *int64
+}
+
+// openMetricsParser parses the OpenMetrics text format, as specified in
+// https://github.com/OpenObservability/OpenMetrics/blob/main/specification/OpenMetrics.md,
+// into metric families.
+type openMetricsParser struct {
+	families map[string]*openMetricsFamily
+	order    []*openMetricsFamily
+}
+
+func parseOpenMetrics(r io.Reader) ([]*dto.MetricFamily, error) {
+	p := &openMetricsParser{families: map[string]*openMetricsFamily{}}
+
+	scanner := bufio.NewScanner(r)
+	eof := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if eof {
+			if line != "" {
+				return nil, fmt.Errorf("unexpected content after # EOF: %s", line)
+			}
+			continue
+		}
+
+		var err error
+		switch {
+		case line == "# EOF":
+			eof = true
+		case strings.HasPrefix(line, "#"):
+			err = p.parseMetadata(line)
+		case strings.TrimSpace(line) == "":
+			err = fmt.Errorf("unexpected empty line")
+		default:
+			err = p.parseSampleLine(line)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	if !eof {
+		return nil, fmt.Errorf("invalid OpenMetrics payload, missing # EOF")
+	}
+
+	var result []*dto.MetricFamily
+	for _, f := range p.order {
+		if len(f.family.Metric) != 0 {
+			result = append(result, f.family)
+		}
+	}
+
+	return result, nil
+}
+
+func (p *openMetricsParser) parseMetadata(line string) error {
+	parts := strings.SplitN(line, " ", 4)
+	if len(parts) < 3 {
+		return nil
+	}
+
+	name := parts[2]
+	value := ""
+	if len(parts) == 4 {
+		value = parts[3]
+	}
+
+	switch parts[1] {
+	case "TYPE":
+		if f, exists := p.families[name]; exists {
+			if f.typed {
+				return fmt.Errorf("duplicate TYPE for metric family %s", name)
+			}
+			if len(f.family.Metric) != 0 {
+				return fmt.Errorf("TYPE for metric family %s after its samples", name)
+			}
+		}
+		return p.setFamilyType(name, value)
+	case "HELP":
+		f := p.getOrCreateFamily(name)
+		f.family.Help = proto.String(unescapeOpenMetrics(value))
+	}
+
+	return nil
+}
+
+// setFamilyType sets the type of the metric family, creating it unless a
+// HELP or UNIT line already did.
+func (p *openMetricsParser) setFamilyType(name, typ string) error {
+	familyName := name
+	var metricType dto.MetricType
+
+	switch typ {
+	case "counter":
+		metricType = dto.MetricType_COUNTER
+		familyName = name + "_total"
+	case "gauge", "stateset":
+		metricType = dto.MetricType_GAUGE
+	case "info":
+		metricType = dto.MetricType_GAUGE
+		familyName = name + "_info"
+	case "histogram":
+		metricType = dto.MetricType_HISTOGRAM
+	case "gaugehistogram":
+		metricType = dto.MetricType_GAUGE_HISTOGRAM
+	case "summary":
+		metricType = dto.MetricType_SUMMARY
+	case "unknown":
+		metricType = dto.MetricType_UNTYPED
+	default:
+		return fmt.Errorf("unknown metric type %q for metric family %s", typ, name)
+	}
+
+	f := p.getOrCreateFamily(name)
+	f.typ = typ
+	f.typed = true
+	f.family.Name = proto.String(familyName)
+	f.family.Type = metricType.Enum()
+
+	return nil
+}
+
+func (p *openMetricsParser) getOrCreateFamily(name string) *openMetricsFamily {
+	if f, ok := p.families[name]; ok {
+		return f
+	}
+
+	f := &openMetricsFamily{
+		name: name,
+		typ:  "unknown",
+		family: &dto.MetricFamily{
+			Name: proto.String(name),
+			Type: dto.MetricType_UNTYPED.Enum(),
+		},
+		series: map[string]*dto.Metric{},
+	}
+	p.families[name] = f
+	p.order = append(p.order, f)
+
+	return f
+}
+
+// familyForSample returns the family a sample belongs to, and the suffix of
+// the sample name relative to the family name.
+func (p *openMetricsParser) familyForSample(name string) (*openMetricsFamily, string) {
+	suffixes := map[string][]string{
+		"counter":        {"_total", "_created"},
+		"info":           {"_info"},
+		"histogram":      {"_bucket", "_count", "_sum", "_created"},
+		"gaugehistogram": {"_bucket", "_gcount", "_gsum"},
+		"summary":        {"_count", "_sum", "_created"},
+	}
+
+	for _, typ := range []string{"counter", "info", "histogram", "gaugehistogram", "summary"} {
+		for _, suffix := range suffixes[typ] {
+			familyName, found := strings.CutSuffix(name, suffix)
+			if !found {
+				continue
+			}
+			if f, ok := p.families[familyName]; ok && f.typ == typ {
+				return f, suffix
+			}
+		}
+	}
+
+	if f, ok := p.families[name]; ok {
+		return f, ""
+	}
+
+	return p.getOrCreateFamily(name), ""
+}
+
+func (p *openMetricsParser) parseSampleLine(line string) error {
+	sample, err := parseOpenMetricsSample(line)
+	if err != nil {
+		return err
+	}
+
+	f, suffix := p.familyForSample(sample.name)
+
+	if suffix == "" && (f.typ == "counter" || f.typ == "info") {
+		return fmt.Errorf("sample %s of %s metric family is missing its suffix", sample.name, f.typ)
+	}
+
+	// created timestamps are not part of the metric families
+	if suffix == "_created" {
+		return nil
+	}
+
+	var le, quantile string
+	var seriesLabels []*dto.LabelPair
+	for _, l := range sample.labels {
+		switch {
+		case l.GetName() == "le" && suffix == "_bucket":
+			le = l.GetValue()
+		case l.GetName() == "quantile" && f.typ == "summary" && suffix == "":
+			quantile = l.GetValue()
+		default:
+			seriesLabels = append(seriesLabels, l)
+		}
+	}
+
+	m := f.getOrCreateSeries(seriesLabels, sample.timestampMs)
+
+	switch f.typ {
+	case "counter":
+		m.Counter = &dto.Counter{Value: proto.Float64(sample.value)}
+	case "gauge", "stateset", "info":
+		m.Gauge = &dto.Gauge{Value: proto.Float64(sample.value)}
+	case "unknown":
+		m.Untyped = &dto.Untyped{Value: proto.Float64(sample.value)}
+	case "histogram", "gaugehistogram":
+		return setOpenMetricsHistogramSample(m, suffix, le, sample.value)
+	case "summary":
+		return setOpenMetricsSummarySample(m, suffix, quantile, sample.value)
+	}
+
+	return nil
+}
+
+func (f *openMetricsFamily) getOrCreateSeries(labels []*dto.LabelPair, timestampMs *int64) *dto.Metric {
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].GetName() < labels[j].GetName()
+	})
+
+	var sb strings.Builder
+	for _, l := range labels {
+		sb.WriteString(l.GetName() + "=" + strconv.Quote(l.GetValue()) + ",")
+	}
+	key := sb.String()
+
+	if m, ok := f.series[key]; ok {
+		return m
+	}
+
+	m := &dto.Metric{Label: labels, TimestampMs: timestampMs}
+	f.series[key] = m
+	f.family.Metric = append(f.family.Metric, m)
+
+	return m
+}
+
+func setOpenMetricsHistogramSample(m *dto.Metric, suffix, le string, value float64) error {
+	if m.Histogram == nil {
+		m.Histogram = &dto.Histogram{}
+	}
+
+	switch suffix {
+	case "_bucket":
+		upperBound, err := parseOpenMetricsFloat(le)
+		if err != nil {
+			return fmt.Errorf("invalid le label %q: %w", le, err)
+		}
+		m.Histogram.Bucket = append(m.Histogram.Bucket, &dto.Bucket{
+			UpperBound:      proto.Float64(upperBound),
+			CumulativeCount: proto.Uint64(uint64(value)),
+		})
+	case "_count", "_gcount":
+		m.Histogram.SampleCount = proto.Uint64(uint64(value))
+	case "_sum", "_gsum":
+		m.Histogram.SampleSum = proto.Float64(value)
+	default:
+		return fmt.Errorf("unexpected histogram sample suffix %q", suffix)
+	}
+
+	return nil
+}
+
+func setOpenMetricsSummarySample(m *dto.Metric, suffix, quantile string, value float64) error {
+	if m.Summary == nil {
+		m.Summary = &dto.Summary{}
+	}
+
+	switch suffix {
+	case "":
+		q, err := parseOpenMetricsFloat(quantile)
+		if err != nil {
+			return fmt.Errorf("invalid quantile label %q: %w", quantile, err)
+		}
+		m.Summary.Quantile = append(m.Summary.Quantile, &dto.Quantile{
+			Quantile: proto.Float64(q),
+			Value:    proto.Float64(value),
+		})
+	case "_count":
+		m.Summary.SampleCount = proto.Uint64(uint64(value))
+	case "_sum":
+		m.Summary.SampleSum = proto.Float64(value)
+	default:
+		return fmt.Errorf("unexpected summary sample suffix %q", suffix)
+	}
+
+	return nil
+}
+
+// parseOpenMetricsSample parses a sample line, in the form
+// name{label="value",...} value [timestamp] [# exemplar].
+func parseOpenMetricsSample(line string) (*openMetricsSample, error) {
+	sample := &openMetricsSample{}
+
+	i := 0
+	for i < len(line) && isMetricNameChar(line[i], i == 0) {
+		i++
+	}
+	if i == 0 {
+		return nil, fmt.Errorf("invalid metric line, no metric name: %s", line)
+	}
+	sample.name = line[:i]
+
+	if i < len(line) && line[i] == '{' {
+		labels, n, err := parseOpenMetricsLabels(line[i:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric line %s: %w", line, err)
+		}
+		sample.labels = labels
+		i += n
+	}
+
+	if i >= len(line) || line[i] != ' ' {
+		return nil, fmt.Errorf("invalid metric line, no value part found: %s", line)
+	}
+
+	rest := line[i+1:]
+	if idx := strings.Index(rest, " # "); idx != -1 {
+		// exemplars are not part of the metric families
+		rest = rest[:idx]
+	}
+
+	parts := strings.Split(rest, " ")
+	if len(parts) > 2 {
+		return nil, fmt.Errorf("invalid metric line, unexpected content: %s", line)
+	}
+
+	value, err := parseOpenMetricsFloat(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metric value: %w", err)
+	}
+	sample.value = value
+
+	if len(parts) == 2 {
+		ts, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse metric timestamp: %w", err)
+		}
+		sample.timestampMs = proto.Int64(int64(math.Round(ts * 1000)))
+	}
+
+	return sample, nil
+}
+
+// parseOpenMetricsLabels parses a label set starting at the opening brace,
+// returning the labels and the number of bytes consumed.
+func parseOpenMetricsLabels(s string) ([]*dto.LabelPair, int, error) {
+	var labels []*dto.LabelPair
+	i := 1
+
+	for {
+		if i >= len(s) {
+			return nil, 0, fmt.Errorf("unterminated label set")
+		}
+		if s[i] == '}' {
+			return labels, i + 1, nil
+		}
+
+		start := i
+		for i < len(s) && isMetricNameChar(s[i], i == start) && s[i] != ':' {
+			i++
+		}
+		if i == start || i+1 >= len(s) || s[i] != '=' || s[i+1] != '"' {
+			return nil, 0, fmt.Errorf("invalid label at position %d", start)
+		}
+		name := s[start:i]
+		i += 2
+
+		var sb strings.Builder
+		for {
+			if i >= len(s) {
+				return nil, 0, fmt.Errorf("unterminated label value for %s", name)
+			}
+			if s[i] == '"' {
+				break
+			}
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					sb.WriteByte('\n')
+				default:
+					sb.WriteByte(s[i])
+				}
+			} else {
+				sb.WriteByte(s[i])
+			}
+			i++
+		}
+		i++
+
+		labels = append(labels, &dto.LabelPair{Name: proto.String(name), Value: proto.String(sb.String())})
+
+		if i < len(s) && s[i] == ',' {
+			i++
+		}
+	}
+}
+
+func isMetricNameChar(c byte, first bool) bool {
+	isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == ':'
+	if first {
+		return isLetter
+	}
+	return isLetter || (c >= '0' && c <= '9')
+}
+
+// parseOpenMetricsFloat parses a number, accepting the +Inf, -Inf and NaN
+// special values in any case.
+func parseOpenMetricsFloat(s string) (float64, error) {
+	switch strings.ToLower(s) {
+	case "+inf", "inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan":
+		return math.NaN(), nil
+	}
+
+	return strconv.ParseFloat(s, 64)
+}
+
+func unescapeOpenMetrics(s string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		if s[i] == 'n' {
+			sb.WriteByte('\n')
+		} else {
+			sb.WriteByte(s[i])
+		}
+	}
+
+	return sb.String()
+}
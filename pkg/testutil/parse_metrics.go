@@ -0,0 +1,132 @@
+package testutil
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// acceptHeader negotiates the protobuf format first, since it is the only one
+// carrying native types, then OpenMetrics and finally the Prometheus text
+// format.
+const acceptHeader = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,` +
+	`application/openmetrics-text;version=1.0.0;q=0.5,` +
+	`text/plain;version=0.0.4;q=0.3,*/*;q=0.1`
+
+const (
+	protobufMediaType    = "application/vnd.google.protobuf"
+	openMetricsMediaType = "application/openmetrics-text"
+)
+
+// parseMetricFamilies parses a metrics payload in the Prometheus text,
+// OpenMetrics or delimited protobuf format. The format is taken from the
+// content type when known, otherwise it is detected from the payload.
+func parseMetricFamilies(r io.Reader, contentType string) ([]*dto.MetricFamily, error) {
+	mediaType := ""
+	if contentType != "" {
+		var err error
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content type %q: %w", contentType, err)
+		}
+	}
+
+	switch mediaType {
+	case protobufMediaType:
+		return parseProtobuf(r)
+	case openMetricsMediaType:
+		return parseOpenMetrics(r)
+	}
+
+	payload, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	if isOpenMetrics(payload) {
+		return parseOpenMetrics(bytes.NewReader(payload))
+	}
+
+	// The text parser requires the last sample to be terminated by a
+	// newline, which hand-written payloads often omit.
+	if len(payload) != 0 && payload[len(payload)-1] != '\n' {
+		payload = append(payload, '\n')
+	}
+
+	return parseText(bytes.NewReader(trimTypeLines(payload)))
+}
+
+// trimTypeLines drops anything following the metric type in TYPE lines,
+// which the text parser rejects but hand-written payloads may use as a
+// trailing comment.
+func trimTypeLines(payload []byte) []byte {
+	lines := bytes.SplitAfter(payload, []byte("\n"))
+	for i, line := range lines {
+		fields := bytes.Fields(line)
+		if len(fields) > 4 && string(fields[0]) == "#" && string(fields[1]) == "TYPE" {
+			lines[i] = append(bytes.Join(fields[:4], []byte(" ")), '\n')
+		}
+	}
+
+	return bytes.Join(lines, nil)
+}
+
+func parseProtobuf(r io.Reader) ([]*dto.MetricFamily, error) {
+	var families []*dto.MetricFamily
+
+	decoder := expfmt.NewDecoder(r, expfmt.FmtProtoDelim)
+	for {
+		mf := &dto.MetricFamily{}
+		if err := decoder.Decode(mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode metrics: %w", err)
+		}
+		families = append(families, mf)
+	}
+
+	return families, nil
+}
+
+func parseText(r io.Reader) ([]*dto.MetricFamily, error) {
+	var parser expfmt.TextParser
+
+	familiesByName, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+
+	families := make([]*dto.MetricFamily, 0, len(familiesByName))
+	for _, mf := range familiesByName {
+		families = append(families, mf)
+	}
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].GetName() < families[j].GetName()
+	})
+
+	return families, nil
+}
+
+// isOpenMetrics reports whether the payload ends with the OpenMetrics
+// "# EOF" marker.
+func isOpenMetrics(payload []byte) bool {
+	last := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(payload))
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) != 0 {
+			last = string(line)
+		}
+	}
+
+	return last == "# EOF"
+}
@@ -0,0 +1,266 @@
+package testutil_test
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/machadovilaca/operator-observability/pkg/testutil"
+)
+
+var _ = Describe("ParseMetrics", func() {
+	var metricsFetcher testutil.MetricsFetcher
+
+	BeforeEach(func() {
+		metricsFetcher = testutil.NewMetricsFetcher("")
+	})
+
+	Context("Prometheus text format", func() {
+		It("should parse escaped quotes and commas in label values", func() {
+			metrics, err := metricsFetcher.LoadMetrics(
+				`operator_info{version="1,2",description="a \"quoted\" value\n"} 1` + "\n",
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(metrics["operator_info"]).To(HaveLen(1))
+			Expect(metrics["operator_info"][0].Labels).To(Equal(map[string]string{
+				"version":     "1,2",
+				"description": "a \"quoted\" value\n",
+			}))
+		})
+
+		It("should parse special values and millisecond timestamps", func() {
+			metrics, err := metricsFetcher.LoadMetrics(
+				"# HELP operator_ratio Ratio of something.\n" +
+					"# TYPE operator_ratio gauge\n" +
+					`operator_ratio{kind="nan"} NaN 1738861782500` + "\n" +
+					`operator_ratio{kind="inf"} +Inf` + "\n" +
+					`operator_ratio{kind="neginf"} -Inf` + "\n",
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			mr := metrics["operator_ratio"]
+			Expect(mr).To(HaveLen(3))
+			Expect(mr[0].Type).To(Equal("gauge"))
+			Expect(mr[0].Help).To(Equal("Ratio of something."))
+			Expect(math.IsNaN(mr[0].Value)).To(BeTrue())
+			Expect(*mr[0].Timestamp).To(BeTemporally("==", time.UnixMilli(1738861782500)))
+			Expect(mr[1].Value).To(Equal(math.Inf(1)))
+			Expect(mr[1].Timestamp).To(BeNil())
+			Expect(mr[2].Value).To(Equal(math.Inf(-1)))
+		})
+
+		It("should return histogram samples under their own names", func() {
+			metrics, err := metricsFetcher.LoadMetrics(
+				"# TYPE operator_latency_seconds histogram\n" +
+					`operator_latency_seconds_bucket{le="0.5"} 1` + "\n" +
+					`operator_latency_seconds_bucket{le="1"} 3` + "\n" +
+					`operator_latency_seconds_bucket{le="+Inf"} 4` + "\n" +
+					"operator_latency_seconds_sum 3.5\n" +
+					"operator_latency_seconds_count 4\n",
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metrics).ToNot(HaveKey("operator_latency_seconds"))
+
+			buckets := metrics["operator_latency_seconds_bucket"]
+			Expect(buckets).To(HaveLen(3))
+			Expect(buckets[2].Type).To(Equal("histogram"))
+			Expect(buckets[2].Labels).To(Equal(map[string]string{"le": "+Inf"}))
+			Expect(buckets[2].Value).To(Equal(4.0))
+			Expect(buckets[2].Family).To(Equal("operator_latency_seconds"))
+			Expect(*buckets[2].Histogram).To(Equal(testutil.HistogramResult{
+				SampleCount: 4,
+				SampleSum:   3.5,
+				Buckets: []testutil.BucketResult{
+					{UpperBound: 0.5, CumulativeCount: 1},
+					{UpperBound: 1, CumulativeCount: 3},
+					{UpperBound: math.Inf(1), CumulativeCount: 4},
+				},
+			}))
+
+			Expect(metrics["operator_latency_seconds_sum"][0].Value).To(Equal(3.5))
+			Expect(metrics["operator_latency_seconds_count"][0].Value).To(Equal(4.0))
+			Expect(metrics["operator_latency_seconds_count"][0].Histogram).To(BeIdenticalTo(buckets[0].Histogram))
+		})
+
+		It("should return summary quantiles under the metric name", func() {
+			metrics, err := metricsFetcher.LoadMetrics(
+				"# TYPE operator_duration_seconds summary\n" +
+					`operator_duration_seconds{quantile="0.5"} 0.2` + "\n" +
+					`operator_duration_seconds{quantile="0.9"} 0.8` + "\n" +
+					"operator_duration_seconds_sum 5\n" +
+					"operator_duration_seconds_count 10\n",
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			quantiles := metrics["operator_duration_seconds"]
+			Expect(quantiles).To(HaveLen(2))
+			Expect(quantiles[0].Type).To(Equal("summary"))
+			Expect(quantiles[0].Labels).To(Equal(map[string]string{"quantile": "0.5"}))
+			Expect(quantiles[0].Value).To(Equal(0.2))
+			Expect(quantiles[1].Labels).To(Equal(map[string]string{"quantile": "0.9"}))
+			Expect(quantiles[1].Value).To(Equal(0.8))
+			Expect(quantiles[1].Summary.SampleSum).To(Equal(5.0))
+			Expect(quantiles[1].Summary.Quantiles).To(Equal([]testutil.QuantileResult{
+				{Quantile: 0.5, Value: 0.2},
+				{Quantile: 0.9, Value: 0.8},
+			}))
+
+			Expect(metrics["operator_duration_seconds_sum"][0].Value).To(Equal(5.0))
+			Expect(metrics["operator_duration_seconds_count"][0].Value).To(Equal(10.0))
+		})
+
+		It("should fail on malformed input", func() {
+			_, err := metricsFetcher.LoadMetrics(`operator_info{version="1} 1` + "\n")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("OpenMetrics format", func() {
+		It("should parse counters, exemplars and second timestamps", func() {
+			metrics, err := metricsFetcher.LoadMetrics(
+				"# TYPE operator_reconcile counter\n" +
+					"# HELP operator_reconcile Number of reconciles.\n" +
+					`operator_reconcile_total{controller="a\"b"} 7 1738861782.5 # {trace_id="abc"} 1.0` + "\n" +
+					`operator_reconcile_created{controller="a\"b"} 1738861700` + "\n" +
+					"# EOF\n",
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			mr := metrics["operator_reconcile_total"]
+			Expect(mr).To(HaveLen(1))
+			Expect(mr[0].Type).To(Equal("counter"))
+			Expect(mr[0].Help).To(Equal("Number of reconciles."))
+			Expect(mr[0].Labels).To(HaveKeyWithValue("controller", `a"b`))
+			Expect(mr[0].Value).To(Equal(7.0))
+			Expect(*mr[0].Timestamp).To(BeTemporally("==", time.UnixMilli(1738861782500)))
+		})
+
+		It("should parse the output of the OpenMetrics encoder", func() {
+			registry := prometheus.NewRegistry()
+
+			counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "operator_reconcile_total",
+				Help: "Number of reconciles.",
+			}, []string{"controller"})
+			counter.WithLabelValues("guestbook").Add(3)
+
+			histogram := prometheus.NewHistogram(prometheus.HistogramOpts{
+				Name:    "operator_reconcile_duration_seconds",
+				Help:    "Reconcile duration.",
+				Buckets: []float64{0.5, 1},
+			})
+			histogram.Observe(0.3)
+			histogram.Observe(0.7)
+
+			registry.MustRegister(counter, histogram)
+
+			families, err := registry.Gather()
+			Expect(err).ToNot(HaveOccurred())
+
+			body := &bytes.Buffer{}
+			encoder := expfmt.NewEncoder(body, expfmt.FmtOpenMetrics_1_0_0)
+			for _, mf := range families {
+				Expect(encoder.Encode(mf)).To(Succeed())
+			}
+			Expect(encoder.(expfmt.Closer).Close()).To(Succeed())
+			Expect(body.String()).To(ContainSubstring("# HELP operator_reconcile Number of reconciles.\n# TYPE operator_reconcile counter\n"))
+
+			metrics, err := metricsFetcher.LoadMetrics(body.String())
+			Expect(err).ToNot(HaveOccurred())
+
+			reconciles := metrics["operator_reconcile_total"]
+			Expect(reconciles).To(HaveLen(1))
+			Expect(reconciles[0].Type).To(Equal("counter"))
+			Expect(reconciles[0].Help).To(Equal("Number of reconciles."))
+			Expect(reconciles[0].Labels).To(Equal(map[string]string{"controller": "guestbook"}))
+			Expect(reconciles[0].Value).To(Equal(3.0))
+
+			durations := metrics["operator_reconcile_duration_seconds_bucket"]
+			Expect(durations).To(HaveLen(3))
+			Expect(durations[0].Type).To(Equal("histogram"))
+			Expect(durations[0].Help).To(Equal("Reconcile duration."))
+			Expect(durations[0].Histogram.SampleCount).To(Equal(uint64(2)))
+			Expect(durations[0].Histogram.Buckets).To(HaveLen(3))
+			Expect(metrics["operator_reconcile_duration_seconds_count"][0].Value).To(Equal(2.0))
+		})
+
+		It("should fail on duplicate TYPE lines", func() {
+			_, err := metricsFetcher.LoadMetrics(
+				"# HELP operator_ready Ready.\n# TYPE operator_ready gauge\n# TYPE operator_ready gauge\noperator_ready 1\n# EOF\n",
+			)
+			Expect(err).To(MatchError(ContainSubstring("duplicate TYPE for metric family operator_ready")))
+		})
+
+		It("should fail on counters without the _total suffix", func() {
+			_, err := metricsFetcher.LoadMetrics(
+				"# TYPE operator_reconcile counter\noperator_reconcile 1\n# EOF\n",
+			)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Content negotiation", func() {
+		var server *ghttp.Server
+
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should request and parse the protobuf format", func() {
+			counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "operator_reconcile_total",
+				Help: "Number of reconciles.",
+			}, []string{"controller"})
+			counter.WithLabelValues("guestbook").Add(3)
+
+			registry := prometheus.NewRegistry()
+			Expect(registry.Register(counter)).To(Succeed())
+
+			families, err := registry.Gather()
+			Expect(err).ToNot(HaveOccurred())
+
+			body := &bytes.Buffer{}
+			encoder := expfmt.NewEncoder(body, expfmt.FmtProtoDelim)
+			for _, mf := range families {
+				Expect(encoder.Encode(mf)).To(Succeed())
+			}
+
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyHeaderKV("Accept", "application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,"+
+					"application/openmetrics-text;version=1.0.0;q=0.5,text/plain;version=0.0.4;q=0.3,*/*;q=0.1"),
+				ghttp.RespondWith(http.StatusOK, body.Bytes(), http.Header{
+					"Content-Type": []string{string(expfmt.FmtProtoDelim)},
+				}),
+			))
+
+			metrics, err := testutil.NewMetricsFetcher(server.URL() + "/metrics").Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			mr := metrics["operator_reconcile_total"]
+			Expect(mr).To(HaveLen(1))
+			Expect(mr[0].Type).To(Equal("counter"))
+			Expect(mr[0].Labels).To(HaveKeyWithValue("controller", "guestbook"))
+			Expect(mr[0].Value).To(Equal(3.0))
+		})
+
+		It("should fail on unexpected status codes", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusUnauthorized, ""))
+
+			_, err := testutil.NewMetricsFetcher(server.URL() + "/metrics").Run()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
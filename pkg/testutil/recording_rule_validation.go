@@ -1,6 +1,9 @@
 package testutil
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/machadovilaca/operator-observability/pkg/operatorrules"
 )
 
@@ -36,3 +39,23 @@ func validateRecordingRuleExpression(recordingRule *operatorrules.RecordingRule)
 
 	return result
 }
+
+// ValidateRecordingRuleNamePrefix returns a validation checking that the
+// recording rule name carries the operator prefix, such as the registry name
+// prefix, either at its start or after the aggregation level of a
+// level:metric:operations name.
+func ValidateRecordingRuleNamePrefix(prefix string) RecordRuleValidation {
+	return func(recordingRule *operatorrules.RecordingRule) []Problem {
+		var result []Problem
+
+		name := recordingRule.MetricsOpts.Name
+		if !strings.HasPrefix(name, prefix) && !strings.Contains(name, ":"+prefix) {
+			result = append(result, Problem{
+				ResourceName: name,
+				Description:  fmt.Sprintf("recording rule name must carry the prefix %s", prefix),
+			})
+		}
+
+		return result
+	}
+}
@@ -51,5 +51,27 @@ var _ = Describe("Validators", func() {
 			Expect(problems).To(HaveLen(1))
 			Expect(problems[0].Description).To(ContainSubstring("recording rule must have an expression"))
 		})
+
+		It("should return error if recording rule name does not carry the prefix", func() {
+			linter.AddCustomRecordRuleValidations(testutil.ValidateRecordingRuleNamePrefix("guestbook_operator_"))
+
+			problems := linter.LintRecordingRules([]operatorrules.RecordingRule{
+				{
+					MetricsOpts: operatormetrics.MetricOpts{Name: "guestbook_operator_ready_pods"},
+					Expr:        intstr.FromString("sum(kube_pod_status_ready)"),
+				},
+				{
+					MetricsOpts: operatormetrics.MetricOpts{Name: "cluster:guestbook_operator_reconcile_count:rate5m"},
+					Expr:        intstr.FromString("sum(rate(guestbook_operator_reconcile_count[5m]))"),
+				},
+				{
+					MetricsOpts: operatormetrics.MetricOpts{Name: "ready_pods"},
+					Expr:        intstr.FromString("sum(kube_pod_status_ready)"),
+				},
+			})
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].ResourceName).To(Equal("ready_pods"))
+			Expect(problems[0].Description).To(ContainSubstring("recording rule name must carry the prefix guestbook_operator_"))
+		})
 	})
 })